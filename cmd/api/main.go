@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/rosset7i/product_crud/config"
 	_ "github.com/rosset7i/product_crud/docs"
@@ -14,6 +16,13 @@ import (
 // @in                        header
 // @name                      Authorization
 func main() {
-	s := server.NewServer(config.New())
+	c := config.New()
+
+	var opts server.Options
+	flag.StringVar(&opts.Storage, "storage", c.DB.Driver, "where data is kept: postgres, sqlite or memory (default from DB_DRIVER)")
+	flag.StringVar(&opts.Seed, "seed", "", "JSON file with demo users and products to load into memory storage")
+	flag.Parse()
+
+	s := server.NewServer(c, opts)
 	s.Run()
 }
@@ -10,16 +10,13 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
 	"github.com/rosset7i/product_crud/config"
-)
-
-const (
-	dialect     = "pgx"
-	fmtDBString = "host=%s user=%s password=%s dbname=%s port=%d sslmode=disable"
+	"github.com/rosset7i/product_crud/internal/infrastructure/database"
+	"github.com/rosset7i/product_crud/internal/infrastructure/sqlite"
 )
 
 var (
 	flags = flag.NewFlagSet("migrate", flag.ExitOnError)
-	dir   = flags.String("dir", "migrations", "directory with migration files")
+	dir   = flags.String("dir", "", "directory with migration files (default migrations, or migrations/sqlite with DB_DRIVER=sqlite)")
 )
 
 func main() {
@@ -38,7 +35,13 @@ func main() {
 	command := args[0]
 
 	c := config.NewDB()
-	dbString := fmt.Sprintf(fmtDBString, c.Host, c.Username, c.Password, c.DBName, c.Port)
+	dialect, dbString, defaultDir, err := target(c)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if *dir == "" {
+		*dir = defaultDir
+	}
 
 	db, err := goose.OpenDBWithDriver(dialect, dbString)
 	if err != nil {
@@ -56,6 +59,20 @@ func main() {
 	}
 }
 
+// target returns the goose dialect, connection string and migrations
+// directory for the configured driver.
+func target(c *config.ConfDB) (dialect, dbString, dir string, err error) {
+	switch c.Driver {
+	case "postgres":
+		dbString, err = database.ConnString(c)
+		return "pgx", dbString, "migrations", err
+	case "sqlite":
+		return "sqlite3", sqlite.DSN(c.SQLitePath), "migrations/sqlite", nil
+	default:
+		return "", "", "", fmt.Errorf("cannot migrate DB_DRIVER %q", c.Driver)
+	}
+}
+
 func usage() {
 	fmt.Println(usagePrefix)
 	flags.PrintDefaults()
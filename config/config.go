@@ -1,7 +1,9 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/go-chi/jwtauth"
@@ -10,9 +12,17 @@ import (
 )
 
 type Conf struct {
-	Auth   ConfAuth
-	Server ConfServer
-	DB     ConfDB
+	Auth       ConfAuth
+	Server     ConfServer
+	DB         ConfDB
+	Webhook    ConfWebhook
+	Events     ConfEvents
+	Cache      ConfCache
+	Facets     ConfFacets
+	Duplicates ConfDuplicates
+	Log        ConfLog
+	Tracing    ConfTracing
+	Shutdown   ConfShutdown
 }
 
 type ConfAuth struct {
@@ -27,21 +37,143 @@ type ConfServer struct {
 	TimeoutWrite time.Duration `env:"SERVER_TIMEOUT_WRITE,required"`
 	TimeoutIdle  time.Duration `env:"SERVER_TIMEOUT_IDLE,required"`
 	Debug        bool          `env:"SERVER_DEBUG,required"`
+	CacheControl string        `env:"SERVER_CACHE_CONTROL,default=no-cache"`
+	// SuggestCacheControl lets clients reuse suggestions for a prefix while
+	// the user keeps typing, instead of revalidating every keystroke.
+	SuggestCacheControl string        `env:"SERVER_SUGGEST_CACHE_CONTROL,default=max-age=60"`
+	MetricsEnabled      bool          `env:"SERVER_METRICS_ENABLED,default=false"`
+	AdminPort           int           `env:"SERVER_ADMIN_PORT,default=9090"`
+	HealthTimeout       time.Duration `env:"SERVER_HEALTH_TIMEOUT,default=2s"`
+	// DrainDelay is how long the server keeps serving with readiness failing
+	// before it shuts down, which should cover a few load balancer health
+	// check intervals. Set it to 0s when nothing routes by readiness.
+	DrainDelay time.Duration `env:"SERVER_DRAIN_DELAY,default=5s"`
 }
 
+// ConfDB describes the connection either field by field or, when DSN is
+// set, as a full connection string that takes precedence over the
+// connection fields.
 type ConfDB struct {
-	Host     string `env:"DB_HOST,required"`
-	Port     int    `env:"DB_PORT,required"`
-	Username string `env:"DB_USER,required"`
-	Password string `env:"DB_PASS,required"`
-	DBName   string `env:"DB_NAME,required"`
+	// Driver picks the storage: postgres, sqlite or memory. SQLitePath is
+	// the database file used by the sqlite driver.
+	Driver     string `env:"DB_DRIVER,default=postgres"`
+	SQLitePath string `env:"DB_SQLITE_PATH,default=product_crud.db"`
+
+	DSN      string `env:"DB_DSN"`
+	Host     string `env:"DB_HOST"`
+	Port     int    `env:"DB_PORT,default=5432"`
+	Username string `env:"DB_USER"`
+	Password string `env:"DB_PASS"`
+	DBName   string `env:"DB_NAME"`
 	Debug    bool   `env:"DB_DEBUG,required"`
+
+	SSLMode     string `env:"DB_SSLMODE,default=disable"`
+	SSLRootCert string `env:"DB_SSLROOTCERT"`
+	SSLCert     string `env:"DB_SSLCERT"`
+	SSLKey      string `env:"DB_SSLKEY"`
+
+	ApplicationName  string        `env:"DB_APPLICATION_NAME,default=product_crud"`
+	StatementTimeout time.Duration `env:"DB_STATEMENT_TIMEOUT,default=0s"`
+
+	MaxConns        int32         `env:"DB_MAX_CONNS,default=0"`
+	MinConns        int32         `env:"DB_MIN_CONNS,default=0"`
+	MaxConnLifetime time.Duration `env:"DB_MAX_CONN_LIFETIME,default=1h"`
+	MaxConnIdleTime time.Duration `env:"DB_MAX_CONN_IDLE_TIME,default=30m"`
+
+	// ReplicaHosts are host or host:port entries separated by ";" that share
+	// every other setting with the primary. ReadYourWrites keeps a user's
+	// reads on the primary for that long after they wrote.
+	ReplicaHosts       []string      `env:"DB_REPLICA_HOSTS"`
+	ReplicaCheckPeriod time.Duration `env:"DB_REPLICA_CHECK_PERIOD,default=5s"`
+	ReadYourWrites     time.Duration `env:"DB_READ_YOUR_WRITES,default=0s"`
+
+	// SearchLanguage is the Postgres text search configuration products are
+	// stemmed with. The search migration reads it too, and both must agree.
+	SearchLanguage string `env:"DB_SEARCH_LANGUAGE,default=english"`
+
+	SlowQueryThreshold time.Duration `env:"DB_SLOW_QUERY_THRESHOLD,default=200ms"`
+	ConnectDeadline    time.Duration `env:"DB_CONNECT_DEADLINE,default=1m"`
+	ConnectBackoff     time.Duration `env:"DB_CONNECT_BACKOFF,default=500ms"`
+	ConnectBackoffMax  time.Duration `env:"DB_CONNECT_BACKOFF_MAX,default=10s"`
+	HealthCheckPeriod  time.Duration `env:"DB_HEALTH_CHECK_PERIOD,default=15s"`
+}
+
+// ConfWebhook tunes deliveries. The wait before a retry doubles from
+// Backoff up to BackoffMax. AllowPrivateTargets lets webhooks reach
+// loopback, private and link-local addresses, which is only meant for local
+// development since any user can register a webhook.
+type ConfWebhook struct {
+	MaxAttempts         int           `env:"WEBHOOK_MAX_ATTEMPTS,default=5"`
+	Backoff             time.Duration `env:"WEBHOOK_BACKOFF,default=1s"`
+	BackoffMax          time.Duration `env:"WEBHOOK_BACKOFF_MAX,default=1h"`
+	Timeout             time.Duration `env:"WEBHOOK_TIMEOUT,default=10s"`
+	AllowPrivateTargets bool          `env:"WEBHOOK_ALLOW_PRIVATE_TARGETS,default=false"`
+}
+
+type ConfEvents struct {
+	ReplaySize   int           `env:"EVENTS_REPLAY_SIZE,default=1024"`
+	ClientBuffer int           `env:"EVENTS_CLIENT_BUFFER,default=64"`
+	Heartbeat    time.Duration `env:"EVENTS_HEARTBEAT,default=15s"`
+	Notify       bool          `env:"EVENTS_NOTIFY,default=false"`
+}
+
+type ConfCache struct {
+	Enabled bool          `env:"CACHE_ENABLED,default=false"`
+	Size    int           `env:"CACHE_SIZE,default=10000"`
+	TTL     time.Duration `env:"CACHE_TTL,default=1m"`
+}
+
+// ConfFacets holds the price bucket bounds, separated by ";", that the
+// price facet counts products in.
+type ConfFacets struct {
+	PriceBuckets []float64 `env:"FACETS_PRICE_BUCKETS,default=10;50;100;500"`
+}
+
+// ConfDuplicates controls the likely duplicate check on product creation,
+// off, warn or reject, and what counts as a likely duplicate there and in
+// the duplicates report. The report is for operators and compares the whole
+// catalog, so it is only served when enabled, on the admin port, and is cut
+// off after ReportTimeout.
+type ConfDuplicates struct {
+	Mode           string        `env:"DUPLICATES_MODE,default=warn"`
+	NameSimilarity float64       `env:"DUPLICATES_NAME_SIMILARITY,default=0.6"`
+	PriceTolerance float64       `env:"DUPLICATES_PRICE_TOLERANCE,default=0.1"`
+	ReportEnabled  bool          `env:"DUPLICATES_REPORT_ENABLED,default=false"`
+	ReportTimeout  time.Duration `env:"DUPLICATES_REPORT_TIMEOUT,default=10s"`
+}
+
+type ConfLog struct {
+	Level  string `env:"LOG_LEVEL,default=info"`
+	Format string `env:"LOG_FORMAT,default=json"`
+}
+
+type ConfTracing struct {
+	Exporter     string  `env:"TRACING_EXPORTER,default=none"`
+	File         string  `env:"TRACING_FILE,default=traces.jsonl"`
+	OTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT,default=localhost:4318"`
+	OTLPInsecure bool    `env:"TRACING_OTLP_INSECURE,default=true"`
+	SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO,default=1"`
+	ServiceName  string  `env:"TRACING_SERVICE_NAME,default=product_crud"`
+}
+
+// ConfShutdown bounds each phase of the graceful shutdown.
+type ConfShutdown struct {
+	HTTPTimeout      time.Duration `env:"SHUTDOWN_HTTP_TIMEOUT,default=10s"`
+	WorkersTimeout   time.Duration `env:"SHUTDOWN_WORKERS_TIMEOUT,default=10s"`
+	TelemetryTimeout time.Duration `env:"SHUTDOWN_TELEMETRY_TIMEOUT,default=5s"`
+	DatabaseTimeout  time.Duration `env:"SHUTDOWN_DATABASE_TIMEOUT,default=5s"`
 }
 
 func New() *Conf {
 	var c Conf
 	if err := envdecode.StrictDecode(&c); err != nil {
-		log.Fatalf("Failed to decode: %s", err)
+		slog.Error("failed to decode config", "err", err)
+		os.Exit(1)
+	}
+
+	if err := c.validate(); err != nil {
+		slog.Error("invalid config", "err", err)
+		os.Exit(1)
 	}
 
 	c.Auth.JwtAuth = jwtauth.New("HS256", []byte(c.Auth.JwtSecret), nil)
@@ -49,10 +181,42 @@ func New() *Conf {
 	return &c
 }
 
+// validate rejects the values that decode fine but would break the server
+// later on.
+func (c *Conf) validate() error {
+	if c.Webhook.MaxAttempts < 1 {
+		return errors.New("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.Webhook.Backoff <= 0 {
+		return errors.New("WEBHOOK_BACKOFF must be positive")
+	}
+	if c.Webhook.BackoffMax < c.Webhook.Backoff {
+		return errors.New("WEBHOOK_BACKOFF_MAX must not be less than WEBHOOK_BACKOFF")
+	}
+	if c.Events.Heartbeat <= 0 {
+		return errors.New("EVENTS_HEARTBEAT must be positive")
+	}
+	if c.DB.ConnectBackoff <= 0 {
+		return errors.New("DB_CONNECT_BACKOFF must be positive")
+	}
+	if c.DB.ConnectBackoffMax < c.DB.ConnectBackoff {
+		return errors.New("DB_CONNECT_BACKOFF_MAX must not be less than DB_CONNECT_BACKOFF")
+	}
+	if len(c.DB.ReplicaHosts) > 0 && c.DB.ReplicaCheckPeriod <= 0 {
+		return errors.New("DB_REPLICA_CHECK_PERIOD must be positive when DB_REPLICA_HOSTS is set")
+	}
+	if c.Duplicates.ReportEnabled && c.Duplicates.ReportTimeout <= 0 {
+		return errors.New("DUPLICATES_REPORT_TIMEOUT must be positive when DUPLICATES_REPORT_ENABLED is set")
+	}
+
+	return nil
+}
+
 func NewDB() *ConfDB {
 	var c ConfDB
 	if err := envdecode.StrictDecode(&c); err != nil {
-		log.Fatalf("Failed to decode: %s", err)
+		slog.Error("failed to decode config", "err", err)
+		os.Exit(1)
 	}
 
 	return &c
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validConf() Conf {
+	return Conf{
+		DB:      ConfDB{ConnectBackoff: 500 * time.Millisecond, ConnectBackoffMax: 10 * time.Second},
+		Events:  ConfEvents{Heartbeat: 15 * time.Second},
+		Webhook: ConfWebhook{MaxAttempts: 5, Backoff: time.Second, BackoffMax: time.Hour},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(c *Conf)
+		valid bool
+	}{
+		{name: "defaults", edit: func(*Conf) {}, valid: true},
+		{name: "no webhook attempts", edit: func(c *Conf) { c.Webhook.MaxAttempts = 0 }},
+		{name: "zero webhook backoff", edit: func(c *Conf) { c.Webhook.Backoff = 0 }},
+		{name: "negative webhook backoff", edit: func(c *Conf) { c.Webhook.Backoff = -time.Second }},
+		{name: "webhook backoff max below backoff", edit: func(c *Conf) { c.Webhook.BackoffMax = time.Millisecond }},
+		{name: "zero heartbeat", edit: func(c *Conf) { c.Events.Heartbeat = 0 }},
+		{name: "negative heartbeat", edit: func(c *Conf) { c.Events.Heartbeat = -time.Second }},
+		{name: "zero connect backoff", edit: func(c *Conf) { c.DB.ConnectBackoff = 0 }},
+		{name: "negative connect backoff", edit: func(c *Conf) { c.DB.ConnectBackoff = -time.Second }},
+		{name: "connect backoff max below backoff", edit: func(c *Conf) { c.DB.ConnectBackoffMax = time.Millisecond }},
+		{name: "no replica check period without replicas", edit: func(c *Conf) { c.DB.ReplicaCheckPeriod = 0 }, valid: true},
+		{name: "zero replica check period", edit: func(c *Conf) {
+			c.DB.ReplicaHosts = []string{"replica-a"}
+			c.DB.ReplicaCheckPeriod = 0
+		}},
+		{name: "replica check period", edit: func(c *Conf) {
+			c.DB.ReplicaHosts = []string{"replica-a"}
+			c.DB.ReplicaCheckPeriod = 5 * time.Second
+		}, valid: true},
+		{name: "zero duplicates report timeout", edit: func(c *Conf) {
+			c.Duplicates.ReportEnabled = true
+			c.Duplicates.ReportTimeout = 0
+		}},
+	}
+	for _, tt := range tests {
+		c := validConf()
+		tt.edit(&c)
+		if tt.valid {
+			assert.NoError(t, c.validate(), tt.name)
+		} else {
+			assert.Error(t, c.validate(), tt.name)
+		}
+	}
+}
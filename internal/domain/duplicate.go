@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"math"
+)
+
+// DuplicateCriteria decides when two products are likely the same one
+// entered twice: names at least NameSimilarity alike, from 0 to 1 on
+// trigrams, and prices at most PriceTolerance apart relative to the higher
+// one.
+type DuplicateCriteria struct {
+	NameSimilarity float64
+	PriceTolerance float64
+}
+
+var (
+	errNameSimilarityOutOfRange = errors.New("name similarity must be between 0 and 1")
+	errPriceToleranceOutOfRange = errors.New("price tolerance must be at least 0 and below 1")
+)
+
+func (c DuplicateCriteria) Validate() error {
+	switch {
+	case c.NameSimilarity <= 0 || c.NameSimilarity > 1:
+		return errNameSimilarityOutOfRange
+	case c.PriceTolerance < 0 || c.PriceTolerance >= 1:
+		return errPriceToleranceOutOfRange
+	}
+
+	return nil
+}
+
+// PriceRange is the range of prices close enough to price, for
+// repositories to narrow candidates down before comparing names.
+func (c DuplicateCriteria) PriceRange(price float64) (low, high float64) {
+	return price * (1 - c.PriceTolerance), price / (1 - c.PriceTolerance)
+}
+
+func (c DuplicateCriteria) ClosePrices(a, b float64) bool {
+	return math.Abs(a-b) <= c.PriceTolerance*max(a, b)
+}
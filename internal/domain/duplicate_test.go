@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicateCriteriaClosePrices(t *testing.T) {
+	c := DuplicateCriteria{NameSimilarity: 0.6, PriceTolerance: 0.1}
+
+	assert.True(t, c.ClosePrices(100, 91))
+	assert.True(t, c.ClosePrices(91, 100))
+	assert.False(t, c.ClosePrices(100, 89))
+
+	low, high := c.PriceRange(90)
+	assert.InDelta(t, 81, low, 1e-9)
+	assert.InDelta(t, 100, high, 1e-9)
+}
+
+func TestDuplicateCriteriaValidate(t *testing.T) {
+	assert.NoError(t, DuplicateCriteria{NameSimilarity: 0.6, PriceTolerance: 0}.Validate())
+	assert.Equal(t, errNameSimilarityOutOfRange, DuplicateCriteria{NameSimilarity: 0, PriceTolerance: 0.1}.Validate())
+	assert.Equal(t, errPriceToleranceOutOfRange, DuplicateCriteria{NameSimilarity: 0.6, PriceTolerance: 1}.Validate())
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "errors"
+
+// Errors every repository implementation returns, so callers can tell
+// them apart regardless of the storage behind the interface.
+var (
+	ErrUserNotFound            = errors.New("user not found")
+	ErrUserAlreadyExists       = errors.New("user already exists")
+	ErrProductNotFound         = errors.New("product not found")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookDeliveryClaimed  = errors.New("webhook delivery was claimed by another attempt")
+	ErrViewNotFound            = errors.New("view not found")
+)
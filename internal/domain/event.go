@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventProductCreated EventType = "product.created"
+	EventProductUpdated EventType = "product.updated"
+	EventProductDeleted EventType = "product.deleted"
+)
+
+var EventTypes = []EventType{
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+}
+
+func (t EventType) Valid() bool {
+	for _, eventType := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event describes a change to a product. Product is nil for deletions. Seq
+// orders the event among every instance when events are shared through the
+// database, and is zero otherwise.
+type Event struct {
+	Id         uuid.UUID
+	Type       EventType
+	ProductId  uuid.UUID
+	Product    *Product
+	OccurredAt time.Time
+	Seq        uint64
+}
+
+func NewProductEvent(eventType EventType, productId uuid.UUID, product *Product) *Event {
+	return &Event{
+		Id:         uuid.New(),
+		Type:       eventType,
+		ProductId:  productId,
+		Product:    product,
+		OccurredAt: time.Now(),
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+)
+
+// PriceBounds split prices into len(bounds)+1 buckets: below the first
+// bound, from each bound up to the next one, and from the last bound up.
+type PriceBounds []float64
+
+var (
+	errPriceBoundsAreRequired  = errors.New("at least one price bound is required")
+	errPriceBoundsNotAscending = errors.New("price bounds must be positive and ascending")
+)
+
+func (b PriceBounds) Validate() error {
+	if len(b) == 0 {
+		return errPriceBoundsAreRequired
+	}
+	for i, bound := range b {
+		if bound <= 0 || (i > 0 && bound <= b[i-1]) {
+			return errPriceBoundsNotAscending
+		}
+	}
+
+	return nil
+}
+
+// Bucket is the index of the bucket price falls in, the same one Postgres'
+// width_bucket picks.
+func (b PriceBounds) Bucket(price float64) int {
+	return sort.Search(len(b), func(i int) bool { return b[i] > price })
+}
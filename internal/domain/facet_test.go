@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceBounds(t *testing.T) {
+	bounds := PriceBounds{10, 50, 100}
+
+	assert.NoError(t, bounds.Validate())
+	assert.Error(t, PriceBounds{}.Validate())
+	assert.Error(t, PriceBounds{10, 10}.Validate())
+	assert.Error(t, PriceBounds{50, 10}.Validate())
+	assert.Error(t, PriceBounds{0, 10}.Validate())
+
+	assert.Equal(t, 0, bounds.Bucket(9.99))
+	assert.Equal(t, 1, bounds.Bucket(10))
+	assert.Equal(t, 2, bounds.Bucket(99.99))
+	assert.Equal(t, 3, bounds.Bucket(100))
+	assert.Equal(t, 3, bounds.Bucket(5000))
+	assert.Equal(t, 0, PriceBounds{}.Bucket(5000))
+}
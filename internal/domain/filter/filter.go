@@ -0,0 +1,109 @@
+// Package filter parses the product filter language, a small expression
+// grammar such as
+//
+//	price ge 10 and (name co "shirt" or created_at gt 2025-01-01)
+//
+// into an AST that repositories compile to their own queries. Only the
+// whitelisted fields can be referenced, and values are typed by the field
+// they are compared with, so a filter never reaches a query as text.
+package filter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Expr is a node of a parsed filter: And, Or, Not or Comparison.
+type Expr interface {
+	expr()
+}
+
+type And struct {
+	Left, Right Expr
+}
+
+type Or struct {
+	Left, Right Expr
+}
+
+type Not struct {
+	Expr Expr
+}
+
+// Comparison compares a field with a value whose Go type follows the
+// field's: string for name, float64 for price, time.Time for timestamps and
+// uuid.UUID for id.
+type Comparison struct {
+	Field Field
+	Op    Op
+	Value any
+}
+
+func (And) expr()        {}
+func (Or) expr()         {}
+func (Not) expr()        {}
+func (Comparison) expr() {}
+
+type Field string
+
+const (
+	FieldId        Field = "id"
+	FieldName      Field = "name"
+	FieldPrice     Field = "price"
+	FieldCreatedAt Field = "created_at"
+	FieldUpdatedAt Field = "updated_at"
+)
+
+// Op is a comparison operator. Co and Sw, contains and starts with, ignore
+// case; the others compare values as they are.
+type Op string
+
+const (
+	Eq Op = "eq"
+	Ne Op = "ne"
+	Gt Op = "gt"
+	Ge Op = "ge"
+	Lt Op = "lt"
+	Le Op = "le"
+	Co Op = "co"
+	Sw Op = "sw"
+)
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindNumber
+	kindTime
+	kindUUID
+)
+
+// fields is the whitelist of what a filter can reference.
+var fields = map[Field]kind{
+	FieldId:        kindUUID,
+	FieldName:      kindString,
+	FieldPrice:     kindNumber,
+	FieldCreatedAt: kindTime,
+	FieldUpdatedAt: kindTime,
+}
+
+var ops = map[kind][]Op{
+	kindString: {Eq, Ne, Co, Sw},
+	kindNumber: {Eq, Ne, Gt, Ge, Lt, Le},
+	kindTime:   {Eq, Ne, Gt, Ge, Lt, Le},
+	kindUUID:   {Eq, Ne},
+}
+
+// timeLayouts are the accepted timestamp values, a date being midnight UTC.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+
+// SyntaxError points at the character, counted from 1, where a filter
+// stopped making sense.
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid filter at position %d: %s", e.Position, e.Message)
+}
@@ -0,0 +1,299 @@
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxLength and maxDepth bound the work a single filter can cause.
+	MaxLength = 1000
+	maxDepth  = 32
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenEnd
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// position is the rune the token starts at, counted from 1.
+	position int
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEnd:
+		return "end of filter"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+
+	return fmt.Sprintf("%q", t.text)
+}
+
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			kind := tokenOpen
+			if r == ')' {
+				kind = tokenClose
+			}
+			tokens = append(tokens, token{kind: kind, text: string(r), position: i + 1})
+			i++
+		case r == '"':
+			start := i
+			var text strings.Builder
+			for i++; ; i++ {
+				if i == len(runes) {
+					return nil, &SyntaxError{Position: start + 1, Message: "unterminated string"}
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+					i++
+				} else if runes[i] == '"' {
+					break
+				}
+				text.WriteRune(runes[i])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), position: start + 1})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()"`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), position: start + 1})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, position: len(runes) + 1}), nil
+}
+
+// Parse reads a filter. Keywords and operators are case insensitive, and
+// and binds tighter than or.
+//
+//	expr       = term { "or" term }
+//	term       = factor { "and" factor }
+//	factor     = "not" factor | "(" expr ")" | comparison
+//	comparison = field op value
+func Parse(input string) (Expr, error) {
+	if length := len([]rune(input)); length > MaxLength {
+		return nil, &SyntaxError{Position: MaxLength + 1, Message: fmt.Sprintf("filter is longer than %d characters", MaxLength)}
+	}
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEnd {
+		return nil, p.errorf("filter is empty")
+	}
+
+	e, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, p.errorf("unexpected %s, expected and, or or the end of the filter", t.describe())
+	}
+
+	return e, nil
+}
+
+type parser struct {
+	tokens []token
+	next   int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEnd {
+		p.next++
+	}
+
+	return t
+}
+
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	if t.kind == tokenWord && strings.EqualFold(t.text, word) {
+		p.next++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Position: p.peek().position, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expr() (Expr, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) term() (Expr, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) factor() (Expr, error) {
+	if p.depth == maxDepth {
+		return nil, p.errorf("filter nests deeper than %d levels", maxDepth)
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
+	if p.keyword("not") {
+		e, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: e}, nil
+	}
+	if p.peek().kind == tokenOpen {
+		p.advance()
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t.kind != tokenClose {
+			return nil, p.errorf("unexpected %s, expected )", t.describe())
+		}
+		p.advance()
+		return e, nil
+	}
+
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Expr, error) {
+	t := p.peek()
+	if t.kind != tokenWord {
+		return nil, p.errorf("unexpected %s, expected a field", t.describe())
+	}
+	field := Field(t.text)
+	kind, ok := fields[field]
+	if !ok {
+		return nil, p.errorf("unknown field %q, expected one of %s", t.text, fieldNames())
+	}
+	p.advance()
+
+	t = p.peek()
+	op := Op(strings.ToLower(t.text))
+	if t.kind != tokenWord || !slices.Contains(ops[kind], op) {
+		return nil, p.errorf("unexpected %s, expected one of %s for %s", t.describe(), opNames(ops[kind]), field)
+	}
+	p.advance()
+
+	value, err := p.value(field, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return Comparison{Field: field, Op: op, Value: value}, nil
+}
+
+func (p *parser) value(field Field, kind kind) (any, error) {
+	t := p.peek()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return nil, p.errorf("unexpected %s, expected a value for %s", t.describe(), field)
+	}
+
+	switch kind {
+	case kindString:
+		if t.kind != tokenString {
+			return nil, p.errorf("%s must be compared with a quoted string, got %s", field, t.describe())
+		}
+		p.advance()
+		return t.text, nil
+	case kindNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil || t.kind != tokenWord {
+			return nil, p.errorf("%s must be compared with a number, got %s", field, t.describe())
+		}
+		p.advance()
+		return n, nil
+	case kindTime:
+		for _, layout := range timeLayouts {
+			if v, err := time.Parse(layout, t.text); err == nil {
+				p.advance()
+				return v, nil
+			}
+		}
+		return nil, p.errorf("%s must be compared with a date or an RFC 3339 timestamp, got %s", field, t.describe())
+	default:
+		id, err := uuid.Parse(t.text)
+		if err != nil {
+			return nil, p.errorf("%s must be compared with a UUID, got %s", field, t.describe())
+		}
+		p.advance()
+		return id, nil
+	}
+}
+
+func fieldNames() string {
+	names := make([]string, 0, len(fields))
+	for f := range fields {
+		names = append(names, string(f))
+	}
+	slices.Sort(names)
+
+	return strings.Join(names, ", ")
+}
+
+func opNames(ops []Op) string {
+	names := make([]string, len(ops))
+	for i, op := range ops {
+		names[i] = string(op)
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,139 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		input    string
+		expected Expr
+	}{
+		{`price ge 10`, Comparison{Field: FieldPrice, Op: Ge, Value: 10.0}},
+		{`name co "shirt"`, Comparison{Field: FieldName, Op: Co, Value: "shirt"}},
+		{`name EQ "say \"hi\" \\o/"`, Comparison{Field: FieldName, Op: Eq, Value: `say "hi" \o/`}},
+		{`created_at gt 2025-01-01`, Comparison{Field: FieldCreatedAt, Op: Gt, Value: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{`updated_at le 2025-01-01T10:30:00+02:00`, Comparison{Field: FieldUpdatedAt, Op: Le, Value: time.Date(2025, 1, 1, 8, 30, 0, 0, time.UTC)}},
+		{`id eq ` + id.String(), Comparison{Field: FieldId, Op: Eq, Value: id}},
+		{
+			`price ge 10 and (name co "shirt" or created_at gt 2025-01-01)`,
+			And{
+				Left: Comparison{Field: FieldPrice, Op: Ge, Value: 10.0},
+				Right: Or{
+					Left:  Comparison{Field: FieldName, Op: Co, Value: "shirt"},
+					Right: Comparison{Field: FieldCreatedAt, Op: Gt, Value: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+		},
+		{
+			`price lt 1 or price gt 5 AND not name sw "x"`,
+			Or{
+				Left: Comparison{Field: FieldPrice, Op: Lt, Value: 1.0},
+				Right: And{
+					Left:  Comparison{Field: FieldPrice, Op: Gt, Value: 5.0},
+					Right: Not{Expr: Comparison{Field: FieldName, Op: Sw, Value: "x"}},
+				},
+			},
+		},
+		{
+			`price ne 1 and price ne 2 and price ne 3`,
+			And{
+				Left: And{
+					Left:  Comparison{Field: FieldPrice, Op: Ne, Value: 1.0},
+					Right: Comparison{Field: FieldPrice, Op: Ne, Value: 2.0},
+				},
+				Right: Comparison{Field: FieldPrice, Op: Ne, Value: 3.0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.input)
+		if assert.NoError(t, err, tt.input) {
+			assertSameExpr(t, tt.expected, e, tt.input)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+		message  string
+	}{
+		{``, 1, "filter is empty"},
+		{`   `, 4, "filter is empty"},
+		{`color eq "red"`, 1, `unknown field "color", expected one of created_at, id, name, price, updated_at`},
+		{`price co 10`, 7, `unexpected "co", expected one of eq, ne, gt, ge, lt, le for price`},
+		{`price ge ten`, 10, `price must be compared with a number, got "ten"`},
+		{`price ge "10"`, 10, `price must be compared with a number, got "10"`},
+		{`name eq shirt`, 9, `name must be compared with a quoted string, got "shirt"`},
+		{`name eq "shirt`, 9, "unterminated string"},
+		{`created_at gt yesterday`, 15, `created_at must be compared with a date or an RFC 3339 timestamp, got "yesterday"`},
+		{`id eq 42`, 7, `id must be compared with a UUID, got "42"`},
+		{`price ge 10 and`, 16, "unexpected end of filter, expected a field"},
+		{`price ge`, 9, "unexpected end of filter, expected a value for price"},
+		{`(price ge 10`, 13, "unexpected end of filter, expected )"},
+		{`price ge 10)`, 12, `unexpected ")", expected and, or or the end of the filter`},
+		{`price ge 10 name eq "x"`, 13, `unexpected "name", expected and, or or the end of the filter`},
+		{`é eq 1`, 1, `unknown field "é", expected one of created_at, id, name, price, updated_at`},
+		{`name co "é" and prix ge 1`, 17, `unknown field "prix", expected one of created_at, id, name, price, updated_at`},
+		{strings.Repeat("(", 40) + "price ge 1" + strings.Repeat(")", 40), 33, "filter nests deeper than 32 levels"},
+		{strings.Repeat(" ", MaxLength+1), MaxLength + 1, "filter is longer than 1000 characters"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var syntaxErr *SyntaxError
+		if assert.True(t, errors.As(err, &syntaxErr), tt.input) {
+			assert.Equal(t, tt.position, syntaxErr.Position, tt.input)
+			assert.Equal(t, tt.message, syntaxErr.Message, tt.input)
+		}
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	_, err := Parse(`price ge ten`)
+	assert.EqualError(t, err, `invalid filter at position 10: price must be compared with a number, got "ten"`)
+}
+
+// assertSameExpr compares times by instant, since a parsed offset is kept.
+func assertSameExpr(t *testing.T, expected, actual Expr, msg string) {
+	t.Helper()
+	switch e := expected.(type) {
+	case And:
+		a, ok := actual.(And)
+		if assert.True(t, ok, msg) {
+			assertSameExpr(t, e.Left, a.Left, msg)
+			assertSameExpr(t, e.Right, a.Right, msg)
+		}
+	case Or:
+		a, ok := actual.(Or)
+		if assert.True(t, ok, msg) {
+			assertSameExpr(t, e.Left, a.Left, msg)
+			assertSameExpr(t, e.Right, a.Right, msg)
+		}
+	case Not:
+		a, ok := actual.(Not)
+		if assert.True(t, ok, msg) {
+			assertSameExpr(t, e.Expr, a.Expr, msg)
+		}
+	case Comparison:
+		a, ok := actual.(Comparison)
+		if !assert.True(t, ok, msg) {
+			return
+		}
+		assert.Equal(t, e.Field, a.Field, msg)
+		assert.Equal(t, e.Op, a.Op, msg)
+		if v, isTime := e.Value.(time.Time); isTime {
+			assert.True(t, v.Equal(a.Value.(time.Time)), msg)
+			return
+		}
+		assert.Equal(t, e.Value, a.Value, msg)
+	}
+}
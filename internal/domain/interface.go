@@ -2,8 +2,10 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
 )
 
 type UserRepository interface {
@@ -12,9 +14,89 @@ type UserRepository interface {
 }
 
 type ProductRepository interface {
-	FetchPaged(ctx context.Context, pageNumber, pageSize int, sort string) ([]*Product, error)
+	// FetchPaged pages through the products matching where, or all of them
+	// when it is nil, by name.
+	FetchPaged(ctx context.Context, pageNumber, pageSize int, sort string, where filter.Expr) ([]*Product, error)
 	FetchById(ctx context.Context, id uuid.UUID) (*Product, error)
+	// FetchByIdForUpdate is FetchById for a product about to be written in
+	// the same transaction. It keeps other writers of the product waiting
+	// until the transaction ends.
+	FetchByIdForUpdate(ctx context.Context, id uuid.UUID) (*Product, error)
+	// FetchByIds returns the products among ids in no particular order,
+	// leaving out the ids no product has.
+	FetchByIds(ctx context.Context, ids []uuid.UUID) ([]*Product, error)
+	// Search pages through the products matching a web search style query,
+	// most relevant first.
+	Search(ctx context.Context, query string, pageNumber, pageSize int) ([]*ProductMatch, error)
+	// Suggest returns up to limit distinct product names for an autocomplete
+	// prefix, tolerating typos.
+	Suggest(ctx context.Context, prefix string, limit int) ([]string, error)
+	// CountByPrice counts the products in each of the len(bounds)+1 price
+	// buckets, among the matches of a search query and of where, either of
+	// which can be left empty.
+	CountByPrice(ctx context.Context, query string, where filter.Expr, bounds PriceBounds) ([]int, error)
+	// FetchSimilar returns up to limit products that are likely duplicates of
+	// product, most similar first.
+	FetchSimilar(ctx context.Context, product *Product, criteria DuplicateCriteria, limit int) ([]*Product, error)
+	// FetchDuplicatePairs returns up to limit pairs of products that are
+	// likely duplicates of each other.
+	FetchDuplicatePairs(ctx context.Context, criteria DuplicateCriteria, limit int) ([][2]*Product, error)
 	Create(ctx context.Context, product *Product) error
 	Update(ctx context.Context, product *Product) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
+
+type WebhookRepository interface {
+	// FetchByOwner returns the webhooks ownerId created, oldest first.
+	FetchByOwner(ctx context.Context, ownerId uuid.UUID) ([]*Webhook, error)
+	FetchById(ctx context.Context, id uuid.UUID) (*Webhook, error)
+	FetchByEvent(ctx context.Context, eventType EventType) ([]*Webhook, error)
+	Create(ctx context.Context, webhook *Webhook) error
+	Update(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type WebhookDeliveryRepository interface {
+	FetchPagedByWebhook(ctx context.Context, webhookId uuid.UUID, pageNumber, pageSize int) ([]*WebhookDelivery, error)
+	FetchById(ctx context.Context, id uuid.UUID) (*WebhookDelivery, error)
+	// FetchPending returns the deliveries still to be attempted, oldest
+	// first.
+	FetchPending(ctx context.Context) ([]*WebhookDelivery, error)
+	Create(ctx context.Context, delivery *WebhookDelivery) error
+	Update(ctx context.Context, delivery *WebhookDelivery) error
+	// Claim records the start of another attempt, holding the delivery
+	// until the given time, and updates delivery to match. It returns
+	// ErrWebhookDeliveryClaimed when the delivery is no longer pending or
+	// had another attempt since it was read.
+	Claim(ctx context.Context, delivery *WebhookDelivery, until time.Time) error
+}
+
+type ViewRepository interface {
+	// FetchVisible returns the views userId owns along with the ones shared
+	// with them, by name.
+	FetchVisible(ctx context.Context, userId uuid.UUID) ([]*View, error)
+	FetchById(ctx context.Context, id uuid.UUID) (*View, error)
+	Create(ctx context.Context, view *View) error
+	Update(ctx context.Context, view *View) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event *Event)
+}
+
+type WebhookDispatcher interface {
+	Dispatch(ctx context.Context, webhook *Webhook, delivery *WebhookDelivery)
+}
+
+// TxManager makes several repository calls atomic. Repositories called with
+// the context passed to fn take part in the transaction.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// InTx reports whether ctx carries a transaction started by WithinTx.
+	InTx(ctx context.Context) bool
+	// AfterCommit runs fn once the transaction ctx carries has committed, or
+	// right away when it carries none. fn does not run when the work it
+	// followed is rolled back.
+	AfterCommit(ctx context.Context, fn func())
+}
@@ -8,6 +8,13 @@ type Product struct {
 	Price float64
 }
 
+// ProductMatch is a product found by a search. Highlight is its name with
+// the matched words wrapped in <mark> tags.
+type ProductMatch struct {
+	Product   *Product
+	Highlight string
+}
+
 var (
 	errNameIsRequired             = errors.New("name is required")
 	errPriceMustBeGreaterThanZero = errors.New("price must be greater than 0")
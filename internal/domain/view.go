@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+)
+
+// ProductColumns are the product fields a view can show, named like the
+// API returns them.
+var ProductColumns = []string{"id", "name", "price", "created_at", "updated_at"}
+
+// View is a named product listing a user saved: a filter, a sort and the
+// columns to show. Only its owner can change it, and the users it is shared
+// with can list and run it.
+type View struct {
+	baseModel
+	OwnerId    uuid.UUID
+	Name       string
+	Filter     string
+	Sort       string
+	Columns    []string
+	SharedWith []uuid.UUID
+}
+
+const (
+	maxViewNameLength = 255
+	maxViewShares     = 100
+)
+
+var (
+	errViewNameIsRequired  = errors.New("name is required")
+	errViewNameIsTooLong   = errors.New("name must be at most 255 characters")
+	errViewSortIsInvalid   = errors.New("sort must be asc or desc")
+	errViewColumnIsUnknown = errors.New("columns must be among id, name, price, created_at and updated_at, without repeats")
+	errViewShareIsInvalid  = errors.New("shared_with must list at most 100 other users, without repeats")
+)
+
+func NewView(ownerId uuid.UUID, name, filter, sort string, columns []string, sharedWith []uuid.UUID) (*View, error) {
+	v := &View{
+		baseModel:  initEntity(),
+		OwnerId:    ownerId,
+		Name:       name,
+		Filter:     filter,
+		Sort:       sort,
+		Columns:    columns,
+		SharedWith: sharedWith,
+	}
+	if v.Sort == "" {
+		v.Sort = "asc"
+	}
+
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (v *View) Validate() error {
+	switch {
+	case v.Name == "":
+		return errViewNameIsRequired
+	case len([]rune(v.Name)) > maxViewNameLength:
+		return errViewNameIsTooLong
+	case v.Sort != "asc" && v.Sort != "desc":
+		return errViewSortIsInvalid
+	}
+
+	for i, column := range v.Columns {
+		if !slices.Contains(ProductColumns, column) || slices.Contains(v.Columns[:i], column) {
+			return errViewColumnIsUnknown
+		}
+	}
+
+	if len(v.SharedWith) > maxViewShares {
+		return errViewShareIsInvalid
+	}
+	for i, userId := range v.SharedWith {
+		if userId == uuid.Nil || userId == v.OwnerId || slices.Contains(v.SharedWith[:i], userId) {
+			return errViewShareIsInvalid
+		}
+	}
+
+	if v.Filter != "" {
+		if _, err := filter.Parse(v.Filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VisibleTo tells whether userId can list and run the view.
+func (v *View) VisibleTo(userId uuid.UUID) bool {
+	return v.OwnerId == userId || slices.Contains(v.SharedWith, userId)
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewView(t *testing.T) {
+	owner := uuid.New()
+
+	v, err := NewView(owner, "Cheap shirts", `price lt 20 and name co "shirt"`, "", []string{"name", "price"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "asc", v.Sort)
+	assert.Equal(t, owner, v.OwnerId)
+	assert.NotEqual(t, uuid.Nil, v.Id)
+
+	_, err = NewView(owner, "", "", "", nil, nil)
+	assert.ErrorIs(t, err, errViewNameIsRequired)
+	_, err = NewView(owner, string(make([]rune, 256)), "", "", nil, nil)
+	assert.ErrorIs(t, err, errViewNameIsTooLong)
+	_, err = NewView(owner, "All", "", "random", nil, nil)
+	assert.ErrorIs(t, err, errViewSortIsInvalid)
+	_, err = NewView(owner, "All", "", "", []string{"name", "secret"}, nil)
+	assert.ErrorIs(t, err, errViewColumnIsUnknown)
+	_, err = NewView(owner, "All", "", "", []string{"name", "name"}, nil)
+	assert.ErrorIs(t, err, errViewColumnIsUnknown)
+	_, err = NewView(owner, "All", "price gt", "", nil, nil)
+	assert.ErrorContains(t, err, "invalid filter at position 9")
+
+	other := uuid.New()
+	_, err = NewView(owner, "All", "", "", nil, []uuid.UUID{other, other})
+	assert.ErrorIs(t, err, errViewShareIsInvalid)
+	_, err = NewView(owner, "All", "", "", nil, []uuid.UUID{owner})
+	assert.ErrorIs(t, err, errViewShareIsInvalid)
+	_, err = NewView(owner, "All", "", "", nil, []uuid.UUID{uuid.Nil})
+	assert.ErrorIs(t, err, errViewShareIsInvalid)
+	_, err = NewView(owner, "All", "", "", nil, make([]uuid.UUID, maxViewShares+1))
+	assert.ErrorIs(t, err, errViewShareIsInvalid)
+}
+
+func TestViewVisibleTo(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	v, err := NewView(owner, "All", "", "", nil, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, v.VisibleTo(owner))
+	assert.False(t, v.VisibleTo(other))
+	v.SharedWith = []uuid.UUID{other}
+	assert.True(t, v.VisibleTo(other))
+	assert.False(t, v.VisibleTo(uuid.New()))
+}
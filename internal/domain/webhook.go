@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook subscribes a url to product events. Only its owner can see or
+// change it.
+type Webhook struct {
+	baseModel
+	OwnerId uuid.UUID
+	Url     string
+	Events  []EventType
+	Secret  string
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent to one webhook. While pending,
+// NextAttemptAt is when the next attempt is due, or until when the attempt
+// in progress holds the delivery.
+type WebhookDelivery struct {
+	baseModel
+	WebhookId     uuid.UUID
+	EventId       uuid.UUID
+	EventType     EventType
+	Payload       []byte
+	Status        DeliveryStatus
+	Attempts      int
+	ResponseCode  int
+	Error         string
+	DeliveredAt   *time.Time
+	NextAttemptAt time.Time
+}
+
+var (
+	errUrlIsRequired     = errors.New("url is required")
+	errUrlIsInvalid      = errors.New("url must be an absolute http or https url")
+	errEventsAreRequired = errors.New("at least one event is required")
+	errEventIsUnknown    = errors.New("unknown event type")
+)
+
+func NewWebhook(ownerId uuid.UUID, rawUrl string, events []EventType, secret string) (*Webhook, error) {
+	if secret == "" {
+		secret = rand.Text()
+	}
+
+	w := &Webhook{
+		baseModel: initEntity(),
+		OwnerId:   ownerId,
+		Url:       rawUrl,
+		Events:    events,
+		Secret:    secret,
+	}
+
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Webhook) Validate() error {
+	switch {
+	case w.Url == "":
+		return errUrlIsRequired
+	case !validUrl(w.Url):
+		return errUrlIsInvalid
+	case len(w.Events) == 0:
+		return errEventsAreRequired
+	}
+
+	for _, event := range w.Events {
+		if !event.Valid() {
+			return errEventIsUnknown
+		}
+	}
+
+	return nil
+}
+
+func NewWebhookDelivery(webhookId, eventId uuid.UUID, eventType EventType, payload []byte) *WebhookDelivery {
+	base := initEntity()
+
+	return &WebhookDelivery{
+		baseModel:     base,
+		WebhookId:     webhookId,
+		EventId:       eventId,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: base.CreatedAt,
+	}
+}
+
+func validUrl(rawUrl string) bool {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
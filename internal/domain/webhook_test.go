@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebhook(t *testing.T) {
+	ownerId := uuid.New()
+	webhook, err := NewWebhook(ownerId, "https://example.com/hook", []EventType{EventProductCreated}, "")
+	assert.Nil(t, err)
+	assert.NotNil(t, webhook)
+	assert.NotEmpty(t, webhook.Id)
+	assert.NotEmpty(t, webhook.Secret)
+	assert.Equal(t, ownerId, webhook.OwnerId)
+	assert.Equal(t, "https://example.com/hook", webhook.Url)
+}
+
+func TestNewWebhookKeepsSecret(t *testing.T) {
+	webhook, err := NewWebhook(uuid.New(), "https://example.com/hook", []EventType{EventProductCreated}, "s3cr3t")
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", webhook.Secret)
+}
+
+func TestWebhookWhenUrlIsRequired(t *testing.T) {
+	webhook, err := NewWebhook(uuid.New(), "", []EventType{EventProductCreated}, "")
+	assert.Nil(t, webhook)
+	assert.Equal(t, errUrlIsRequired, err)
+}
+
+func TestWebhookWhenUrlIsInvalid(t *testing.T) {
+	webhook, err := NewWebhook(uuid.New(), "ftp://example.com", []EventType{EventProductCreated}, "")
+	assert.Nil(t, webhook)
+	assert.Equal(t, errUrlIsInvalid, err)
+}
+
+func TestWebhookWhenEventsAreRequired(t *testing.T) {
+	webhook, err := NewWebhook(uuid.New(), "https://example.com/hook", nil, "")
+	assert.Nil(t, webhook)
+	assert.Equal(t, errEventsAreRequired, err)
+}
+
+func TestWebhookWhenEventIsUnknown(t *testing.T) {
+	webhook, err := NewWebhook(uuid.New(), "https://example.com/hook", []EventType{"product.exploded"}, "")
+	assert.Nil(t, webhook)
+	assert.Equal(t, errEventIsUnknown, err)
+}
+
+func TestNewWebhookDelivery(t *testing.T) {
+	webhookId := uuid.New()
+	delivery := NewWebhookDelivery(webhookId, uuid.New(), EventProductDeleted, []byte("{}"))
+	assert.NotEmpty(t, delivery.Id)
+	assert.Equal(t, webhookId, delivery.WebhookId)
+	assert.Equal(t, DeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.Attempts)
+	assert.Equal(t, delivery.CreatedAt, delivery.NextAttemptAt)
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+	"golang.org/x/sync/singleflight"
+)
+
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Size   int    `json:"size"`
+}
+
+// ProductRepository is a read-through cache in front of another
+// domain.ProductRepository. Only products fetched by id are cached;
+// concurrent misses for the same id share a single lookup. Reads made
+// within a transaction skip the cache, since they may see writes that are
+// not committed yet and must run on the transaction itself, and writes made
+// within one evict the product again once it commits.
+type ProductRepository struct {
+	next       domain.ProductRepository
+	txManager  domain.TxManager
+	lru        *LRU[uuid.UUID, domain.Product]
+	group      singleflight.Group
+	generation atomic.Uint64
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+func NewProductRepository(next domain.ProductRepository, txManager domain.TxManager, size int, ttl time.Duration) *ProductRepository {
+	return &ProductRepository{
+		next:      next,
+		txManager: txManager,
+		lru:       NewLRU[uuid.UUID, domain.Product](size, ttl),
+	}
+}
+
+func (r *ProductRepository) FetchPaged(ctx context.Context, pageNumber, pageSize int, sort string, where filter.Expr) ([]*domain.Product, error) {
+	return r.next.FetchPaged(ctx, pageNumber, pageSize, sort, where)
+}
+
+func (r *ProductRepository) FetchById(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	if r.txManager.InTx(ctx) {
+		return r.next.FetchById(ctx, id)
+	}
+	if p, ok := r.lru.Get(id); ok {
+		r.hits.Add(1)
+		return &p, nil
+	}
+	r.misses.Add(1)
+
+	v, err, _ := r.group.Do(id.String(), func() (any, error) {
+		generation := r.generation.Load()
+		p, err := r.next.FetchById(context.WithoutCancel(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		// skip caching when a write raced with the lookup
+		if generation == r.generation.Load() {
+			r.lru.Add(id, *p)
+		}
+
+		return *p, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p := v.(domain.Product)
+	return &p, nil
+}
+
+// FetchByIdForUpdate always goes to the next repository, since the product
+// is about to change.
+func (r *ProductRepository) FetchByIdForUpdate(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	return r.next.FetchByIdForUpdate(ctx, id)
+}
+
+// FetchByIds looks up the ids it misses in one call to the next
+// repository. Ids no product has are not cached, like in FetchById.
+func (r *ProductRepository) FetchByIds(ctx context.Context, ids []uuid.UUID) ([]*domain.Product, error) {
+	if r.txManager.InTx(ctx) {
+		return r.next.FetchByIds(ctx, ids)
+	}
+	products := make([]*domain.Product, 0, len(ids))
+	var missed []uuid.UUID
+	for _, id := range ids {
+		if p, ok := r.lru.Get(id); ok {
+			products = append(products, &p)
+		} else {
+			missed = append(missed, id)
+		}
+	}
+	r.hits.Add(uint64(len(products)))
+	r.misses.Add(uint64(len(missed)))
+	if len(missed) == 0 {
+		return products, nil
+	}
+
+	generation := r.generation.Load()
+	fetched, err := r.next.FetchByIds(ctx, missed)
+	if err != nil {
+		return nil, err
+	}
+	// skip caching when a write raced with the lookup
+	if generation == r.generation.Load() {
+		for _, p := range fetched {
+			r.lru.Add(p.Id, *p)
+		}
+	}
+
+	return append(products, fetched...), nil
+}
+
+func (r *ProductRepository) Search(ctx context.Context, query string, pageNumber, pageSize int) ([]*domain.ProductMatch, error) {
+	return r.next.Search(ctx, query, pageNumber, pageSize)
+}
+
+func (r *ProductRepository) Suggest(ctx context.Context, prefix string, limit int) ([]string, error) {
+	return r.next.Suggest(ctx, prefix, limit)
+}
+
+func (r *ProductRepository) CountByPrice(ctx context.Context, query string, where filter.Expr, bounds domain.PriceBounds) ([]int, error) {
+	return r.next.CountByPrice(ctx, query, where, bounds)
+}
+
+func (r *ProductRepository) FetchSimilar(ctx context.Context, product *domain.Product, criteria domain.DuplicateCriteria, limit int) ([]*domain.Product, error) {
+	return r.next.FetchSimilar(ctx, product, criteria, limit)
+}
+
+func (r *ProductRepository) FetchDuplicatePairs(ctx context.Context, criteria domain.DuplicateCriteria, limit int) ([][2]*domain.Product, error) {
+	return r.next.FetchDuplicatePairs(ctx, criteria, limit)
+}
+
+func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
+	defer r.invalidateWrite(ctx, product.Id)
+	return r.next.Create(ctx, product)
+}
+
+func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	defer r.invalidateWrite(ctx, product.Id)
+	return r.next.Update(ctx, product)
+}
+
+func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer r.invalidateWrite(ctx, id)
+	return r.next.Delete(ctx, id)
+}
+
+// Publish evicts the product an event refers to, which keeps the cache
+// coherent with writes made by other instances.
+func (r *ProductRepository) Publish(_ context.Context, event *domain.Event) {
+	r.invalidate(event.ProductId)
+}
+
+func (r *ProductRepository) Stats() Stats {
+	return Stats{
+		Hits:   r.hits.Load(),
+		Misses: r.misses.Load(),
+		Size:   r.lru.Len(),
+	}
+}
+
+// invalidateWrite evicts a product just written. Within a transaction it
+// evicts it again after the commit, as lookups outside the transaction still
+// read the old product until then.
+func (r *ProductRepository) invalidateWrite(ctx context.Context, id uuid.UUID) {
+	r.invalidate(id)
+	if r.txManager.InTx(ctx) {
+		r.txManager.AfterCommit(ctx, func() { r.invalidate(id) })
+	}
+}
+
+func (r *ProductRepository) invalidate(id uuid.UUID) {
+	r.generation.Add(1)
+	r.group.Forget(id.String())
+	r.lru.Remove(id)
+}
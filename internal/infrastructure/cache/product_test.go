@@ -0,0 +1,265 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+type productRepositoryStub struct {
+	domain.ProductRepository
+	product *domain.Product
+	// pending holds what a transaction wrote until txManagerStub commits it
+	pending *domain.Product
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (r *productRepositoryStub) FetchById(_ context.Context, id uuid.UUID) (*domain.Product, error) {
+	r.calls.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	if r.product == nil || r.product.Id != id {
+		return nil, errors.New("product not found")
+	}
+	p := *r.product
+	return &p, nil
+}
+
+func (r *productRepositoryStub) FetchByIds(_ context.Context, ids []uuid.UUID) ([]*domain.Product, error) {
+	r.calls.Add(1)
+	products := make([]*domain.Product, 0)
+	for _, id := range ids {
+		if r.product != nil && r.product.Id == id {
+			p := *r.product
+			products = append(products, &p)
+		}
+	}
+	return products, nil
+}
+
+func (r *productRepositoryStub) Update(ctx context.Context, product *domain.Product) error {
+	p := *product
+	if ctx.Value(txKey{}) != nil {
+		r.pending = &p
+		return nil
+	}
+	r.product = &p
+	return nil
+}
+
+type txKey struct{}
+
+type txManagerStub struct {
+	next  *productRepositoryStub
+	hooks []func()
+}
+
+func (m *txManagerStub) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		m.hooks = nil
+		return err
+	}
+	if m.next != nil && m.next.pending != nil {
+		m.next.product, m.next.pending = m.next.pending, nil
+	}
+	for _, hook := range m.hooks {
+		hook()
+	}
+	m.hooks = nil
+	return nil
+}
+
+func (m *txManagerStub) InTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
+func (m *txManagerStub) AfterCommit(ctx context.Context, fn func()) {
+	if m.InTx(ctx) {
+		m.hooks = append(m.hooks, fn)
+		return
+	}
+	fn()
+}
+
+func TestProductRepositoryCachesFetchById(t *testing.T) {
+	p, _ := domain.NewProduct("Product", 10)
+	next := &productRepositoryStub{product: p}
+	repo := NewProductRepository(next, &txManagerStub{}, 10, time.Minute)
+
+	first, err := repo.FetchById(context.Background(), p.Id)
+	assert.Nil(t, err)
+	second, err := repo.FetchById(context.Background(), p.Id)
+	assert.Nil(t, err)
+
+	assert.Equal(t, int32(1), next.calls.Load())
+	assert.Equal(t, first, second)
+	assert.Equal(t, Stats{Hits: 1, Misses: 1, Size: 1}, repo.Stats())
+}
+
+func TestProductRepositoryFetchByIdsLooksUpMissesOnly(t *testing.T) {
+	p, _ := domain.NewProduct("Product", 10)
+	next := &productRepositoryStub{product: p}
+	repo := NewProductRepository(next, &txManagerStub{}, 10, time.Minute)
+	missing := uuid.New()
+
+	first, err := repo.FetchByIds(context.Background(), []uuid.UUID{p.Id, missing})
+	assert.Nil(t, err)
+	second, err := repo.FetchByIds(context.Background(), []uuid.UUID{p.Id})
+	assert.Nil(t, err)
+	cached, err := repo.FetchById(context.Background(), p.Id)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []*domain.Product{p}, first)
+	assert.Equal(t, first, second)
+	assert.Equal(t, p, cached)
+	assert.Equal(t, int32(1), next.calls.Load())
+	assert.Equal(t, Stats{Hits: 2, Misses: 2, Size: 1}, repo.Stats())
+}
+
+func TestProductRepositorySkipsCacheWithinTx(t *testing.T) {
+	p, _ := domain.NewProduct("Product", 10)
+	next := &productRepositoryStub{product: p}
+	tx := &txManagerStub{}
+	repo := NewProductRepository(next, tx, 10, time.Minute)
+
+	_ = tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		for range 2 {
+			_, err := repo.FetchById(ctx, p.Id)
+			assert.Nil(t, err)
+			_, err = repo.FetchByIds(ctx, []uuid.UUID{p.Id})
+			assert.Nil(t, err)
+		}
+		return nil
+	})
+
+	assert.Equal(t, int32(4), next.calls.Load())
+	assert.Equal(t, Stats{}, repo.Stats())
+}
+
+func TestProductRepositoryReturnsCopies(t *testing.T) {
+	p, _ := domain.NewProduct("Product", 10)
+	repo := NewProductRepository(&productRepositoryStub{product: p}, &txManagerStub{}, 10, time.Minute)
+
+	first, _ := repo.FetchById(context.Background(), p.Id)
+	first.Name = "Changed"
+	second, _ := repo.FetchById(context.Background(), p.Id)
+
+	assert.Equal(t, "Product", second.Name)
+}
+
+func TestProductRepositoryDoesNotCacheErrors(t *testing.T) {
+	next := &productRepositoryStub{}
+	repo := NewProductRepository(next, &txManagerStub{}, 10, time.Minute)
+
+	_, err := repo.FetchById(context.Background(), uuid.New())
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, repo.Stats().Size)
+}
+
+func TestProductRepositoryInvalidatesOnUpdate(t *testing.T) {
+	p, _ := domain.NewProduct("Product", 10)
+	next := &productRepositoryStub{product: p}
+	repo := NewProductRepository(next, &txManagerStub{}, 10, time.Minute)
+
+	cached, _ := repo.FetchById(context.Background(), p.Id)
+	cached.Name = "Renamed"
+	assert.Nil(t, repo.Update(context.Background(), cached))
+
+	fresh, _ := repo.FetchById(context.Background(), p.Id)
+	assert.Equal(t, "Renamed", fresh.Name)
+	assert.Equal(t, int32(2), next.calls.Load())
+}
+
+func TestProductRepositoryEvictsReadsMadeBeforeCommit(t *testing.T) {
+	p, _ := domain.NewProduct("Product", 10)
+	next := &productRepositoryStub{product: p}
+	tx := &txManagerStub{next: next}
+	repo := NewProductRepository(next, tx, 10, time.Minute)
+
+	err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		renamed := *p
+		renamed.Name = "Renamed"
+		if err := repo.Update(ctx, &renamed); err != nil {
+			return err
+		}
+		// a lookup outside the transaction still reads, and caches, the
+		// product from before the write
+		stale, err := repo.FetchById(context.Background(), p.Id)
+		assert.Nil(t, err)
+		assert.Equal(t, "Product", stale.Name)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	fresh, err := repo.FetchById(context.Background(), p.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "Renamed", fresh.Name)
+}
+
+func TestProductRepositoryInvalidatesOnEvent(t *testing.T) {
+	p, _ := domain.NewProduct("Product", 10)
+	repo := NewProductRepository(&productRepositoryStub{product: p}, &txManagerStub{}, 10, time.Minute)
+
+	_, _ = repo.FetchById(context.Background(), p.Id)
+	repo.Publish(context.Background(), domain.NewProductEvent(domain.EventProductDeleted, p.Id, nil))
+
+	assert.Equal(t, 0, repo.Stats().Size)
+}
+
+func TestProductRepositoryCollapsesConcurrentMisses(t *testing.T) {
+	p, _ := domain.NewProduct("Product", 10)
+	next := &productRepositoryStub{product: p, release: make(chan struct{})}
+	repo := NewProductRepository(next, &txManagerStub{}, 10, time.Minute)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.FetchById(context.Background(), p.Id)
+			assert.Nil(t, err)
+		}()
+	}
+	assert.Eventually(t, func() bool { return repo.Stats().Misses == 10 }, time.Second, time.Millisecond)
+	// give the last callers time to join the in-flight lookup
+	time.Sleep(20 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), next.calls.Load())
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	_, _ = c.Get("a")
+	c.Add("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	now := time.Now()
+	c := NewLRU[string, int](2, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Add("a", 1)
+
+	now = now.Add(2 * time.Minute)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
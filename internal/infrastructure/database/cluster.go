@@ -0,0 +1,238 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rosset7i/product_crud/config"
+)
+
+// Cluster routes reads to healthy read replicas and writes to the primary.
+// Without replicas, or when every replica is down, everything goes to the
+// primary.
+type Cluster struct {
+	primary  *pgxpool.Pool
+	replicas []*replica
+	next     atomic.Uint64
+	logger   *slog.Logger
+
+	checkPeriod    time.Duration
+	readYourWrites time.Duration
+	lastWrites     sync.Map // session key -> time.Time of its last write
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type replica struct {
+	host    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// NewCluster opens a pool per replica in c.ReplicaHosts with the same
+// settings as the primary. Replica pools connect lazily and only take reads
+// once a health check succeeded, so a replica that is down does not hold up
+// the start.
+func NewCluster(primary *pgxpool.Pool, c *config.ConfDB, logger *slog.Logger, tracers ...pgx.QueryTracer) (*Cluster, error) {
+	cluster := &Cluster{
+		primary:        primary,
+		logger:         logger,
+		checkPeriod:    c.ReplicaCheckPeriod,
+		readYourWrites: c.ReadYourWrites,
+		done:           make(chan struct{}),
+	}
+
+	for _, host := range c.ReplicaHosts {
+		config, err := poolConfig(c, tracers)
+		if err != nil {
+			cluster.closeReplicas()
+			return nil, err
+		}
+		config.ConnConfig.Host, config.ConnConfig.Port = splitHostPort(host, config.ConnConfig.Port)
+		config.ConnConfig.Fallbacks = nil
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), config)
+		if err != nil {
+			cluster.closeReplicas()
+			return nil, fmt.Errorf("replica %s: %w", host, err)
+		}
+		cluster.replicas = append(cluster.replicas, &replica{host: host, pool: pool})
+	}
+
+	return cluster, nil
+}
+
+// Primary is the pool every write and every pinned read goes to.
+func (c *Cluster) Primary() *pgxpool.Pool {
+	return c.primary
+}
+
+// Reader picks a healthy replica round-robin, unless the session wrote
+// recently and must read its own writes from the primary.
+func (c *Cluster) Reader(ctx context.Context) *pgxpool.Pool {
+	if len(c.replicas) == 0 || c.pinned(ctx) {
+		return c.primary
+	}
+
+	healthy := 0
+	for _, r := range c.replicas {
+		if r.healthy.Load() {
+			healthy++
+		}
+	}
+	if healthy == 0 {
+		return c.primary
+	}
+
+	// spread evenly over the healthy replicas only, so a replica that is
+	// down does not double the load of its neighbour
+	n := int(c.next.Add(1) % uint64(healthy))
+	for _, r := range c.replicas {
+		if !r.healthy.Load() {
+			continue
+		}
+		if n == 0 {
+			return r.pool
+		}
+		n--
+	}
+
+	return c.primary
+}
+
+// Writer returns the primary and pins the rest of the request, and for the
+// read-your-writes window the rest of the session, to it.
+func (c *Cluster) Writer(ctx context.Context) *pgxpool.Pool {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+		if s.key != "" && c.readYourWrites > 0 && len(c.replicas) > 0 {
+			c.lastWrites.Store(s.key, time.Now())
+		}
+	}
+
+	return c.primary
+}
+
+// Start checks the replicas right away and then every check period.
+func (c *Cluster) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.check(ctx)
+
+	go func() {
+		defer close(c.done)
+		if len(c.replicas) == 0 {
+			return
+		}
+
+		ticker := time.NewTicker(c.checkPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.check(ctx)
+			}
+		}
+	}()
+}
+
+// Close stops the health checks and closes the replica pools. The primary
+// belongs to the caller.
+func (c *Cluster) Close() {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+	c.closeReplicas()
+}
+
+func (c *Cluster) check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, r := range c.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctxPing, cancel := context.WithTimeout(ctx, pingTimeout)
+			defer cancel()
+
+			err := r.pool.Ping(ctxPing)
+			healthy := err == nil
+			if r.healthy.Swap(healthy) != healthy {
+				if healthy {
+					c.logger.Info("replica is up", "replica", r.host)
+				} else {
+					c.logger.Warn("replica is down, reading from the other replicas or the primary", "replica", r.host, "err", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// forget sessions whose window is over
+	c.lastWrites.Range(func(key, value any) bool {
+		if time.Since(value.(time.Time)) > c.readYourWrites {
+			c.lastWrites.Delete(key)
+		}
+		return true
+	})
+}
+
+func (c *Cluster) pinned(ctx context.Context) bool {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	if !ok {
+		return false
+	}
+	if s.wrote.Load() {
+		return true
+	}
+	if s.key == "" || c.readYourWrites <= 0 {
+		return false
+	}
+	last, ok := c.lastWrites.Load(s.key)
+
+	return ok && time.Since(last.(time.Time)) < c.readYourWrites
+}
+
+func (c *Cluster) closeReplicas() {
+	for _, r := range c.replicas {
+		r.pool.Close()
+	}
+}
+
+type sessionKey struct{}
+
+type session struct {
+	key   string
+	wrote atomic.Bool
+}
+
+// WithSession scopes read-your-writes pinning to a request. key identifies
+// the caller across requests, typically the user id, and may be empty to
+// pin within the request only.
+func WithSession(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{key: key})
+}
+
+func splitHostPort(hostport string, defaultPort uint16) (string, uint16) {
+	host, portString, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport, defaultPort
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return host, defaultPort
+	}
+
+	return host, uint16(port)
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rosset7i/product_crud/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCluster builds pools that never connect, which is enough to check
+// the routing.
+func newTestCluster(t *testing.T, c *config.ConfDB) *Cluster {
+	t.Helper()
+	c.Host = "primary"
+	primary, err := pgxpool.New(context.Background(), "host=primary")
+	assert.NoError(t, err)
+	cluster, err := NewCluster(primary, c, slog.New(slog.DiscardHandler))
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		cluster.Close()
+		primary.Close()
+	})
+
+	return cluster
+}
+
+func TestReaderWithoutReplicasUsesPrimary(t *testing.T) {
+	cluster := newTestCluster(t, &config.ConfDB{})
+
+	assert.Same(t, cluster.Primary(), cluster.Reader(context.Background()))
+}
+
+func TestReaderRoundRobinsHealthyReplicas(t *testing.T) {
+	cluster := newTestCluster(t, &config.ConfDB{ReplicaHosts: []string{"replica-a", "replica-b:5433", "replica-c"}})
+	assert.Equal(t, "replica-b", cluster.replicas[1].pool.Config().ConnConfig.Host)
+	assert.Equal(t, uint16(5433), cluster.replicas[1].pool.Config().ConnConfig.Port)
+
+	cluster.replicas[0].healthy.Store(true)
+	cluster.replicas[2].healthy.Store(true)
+
+	seen := make(map[*pgxpool.Pool]int)
+	for range 4 {
+		seen[cluster.Reader(context.Background())]++
+	}
+	assert.Equal(t, map[*pgxpool.Pool]int{cluster.replicas[0].pool: 2, cluster.replicas[2].pool: 2}, seen)
+}
+
+func TestReaderFallsBackToPrimaryWhenReplicasAreDown(t *testing.T) {
+	cluster := newTestCluster(t, &config.ConfDB{ReplicaHosts: []string{"replica-a"}})
+
+	assert.Same(t, cluster.Primary(), cluster.Reader(context.Background()))
+}
+
+func TestWriterPinsTheRequestAndSession(t *testing.T) {
+	cluster := newTestCluster(t, &config.ConfDB{ReplicaHosts: []string{"replica-a"}, ReadYourWrites: time.Minute})
+	cluster.replicas[0].healthy.Store(true)
+	replica := cluster.replicas[0].pool
+
+	ctx := WithSession(context.Background(), "user-1")
+	assert.Same(t, replica, cluster.Reader(ctx))
+
+	cluster.Writer(ctx)
+	assert.Same(t, cluster.Primary(), cluster.Reader(ctx))
+
+	// a later request by the same user reads its write from the primary
+	assert.Same(t, cluster.Primary(), cluster.Reader(WithSession(context.Background(), "user-1")))
+	assert.Same(t, replica, cluster.Reader(WithSession(context.Background(), "user-2")))
+	assert.Same(t, replica, cluster.Reader(context.Background()))
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/rosset7i/product_crud/config"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/infrastructure/repotest"
+	"github.com/rosset7i/product_crud/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPool connects to TEST_DATABASE_DSN and migrates it, skipping the test
+// when no database is configured, unless running in CI where the suite must
+// not silently go missing. Tables are emptied by the repositories the
+// conformance suite creates.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatal("TEST_DATABASE_DSN must be set in CI")
+		}
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(pool.Close)
+
+	goose.SetBaseFS(migrations.FS)
+	assert.NoError(t, goose.SetDialect("postgres"))
+	if !assert.NoError(t, goose.Up(stdlib.OpenDBFromPool(pool), ".")) {
+		t.FailNow()
+	}
+
+	return pool
+}
+
+func truncate(t *testing.T, pool *pgxpool.Pool, tables string) {
+	t.Helper()
+	_, err := pool.Exec(context.Background(), "TRUNCATE "+tables+" CASCADE")
+	assert.NoError(t, err)
+}
+
+func TestProductRepositoryConformance(t *testing.T) {
+	pool := testPool(t)
+	cluster, err := NewCluster(pool, &config.ConfDB{}, slog.New(slog.DiscardHandler))
+	assert.NoError(t, err)
+
+	repotest.ProductRepository(t, func(t *testing.T) domain.ProductRepository {
+		truncate(t, pool, "products")
+		return NewProductRepository(cluster, "english", slog.New(slog.DiscardHandler))
+	})
+}
+
+func TestProductUpdatesWithinTx(t *testing.T) {
+	pool := testPool(t)
+	cluster, err := NewCluster(pool, &config.ConfDB{}, slog.New(slog.DiscardHandler))
+	assert.NoError(t, err)
+
+	repotest.ProductUpdatesWithinTx(t, func(t *testing.T) (domain.ProductRepository, domain.TxManager) {
+		truncate(t, pool, "products")
+		logger := slog.New(slog.DiscardHandler)
+		return NewProductRepository(cluster, "english", logger), NewTxManager(pool, logger)
+	})
+}
+
+func TestUserRepositoryConformance(t *testing.T) {
+	pool := testPool(t)
+
+	repotest.UserRepository(t, func(t *testing.T) domain.UserRepository {
+		truncate(t, pool, "users")
+		return NewUserRepository(pool, slog.New(slog.DiscardHandler))
+	})
+}
+
+func TestViewRepositoryConformance(t *testing.T) {
+	pool := testPool(t)
+
+	repotest.ViewRepository(t, func(t *testing.T) (domain.UserRepository, domain.ViewRepository) {
+		truncate(t, pool, "users, views")
+		return NewUserRepository(pool, slog.New(slog.DiscardHandler)), NewViewRepository(pool, slog.New(slog.DiscardHandler))
+	})
+}
+
+func TestWebhookRepositoryConformance(t *testing.T) {
+	pool := testPool(t)
+	logger := slog.New(slog.DiscardHandler)
+
+	repotest.WebhookRepository(t, func(t *testing.T) (domain.UserRepository, domain.WebhookRepository, domain.WebhookDeliveryRepository) {
+		truncate(t, pool, "users, webhooks, webhook_deliveries")
+		return NewUserRepository(pool, logger), NewWebhookRepository(pool, logger), NewWebhookDeliveryRepository(pool, logger)
+	})
+}
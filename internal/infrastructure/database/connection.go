@@ -0,0 +1,70 @@
+package database
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/rosset7i/product_crud/config"
+)
+
+var errHostIsRequired = errors.New("DB_HOST or DB_DSN is required")
+
+// ConnString builds the keyword/value connection string shared by the API
+// and cmd/migrate. A configured DSN is returned untouched.
+func ConnString(c *config.ConfDB) (string, error) {
+	if c.DSN != "" {
+		return c.DSN, nil
+	}
+	if c.Host == "" {
+		return "", errHostIsRequired
+	}
+
+	var port string
+	if c.Port > 0 {
+		port = strconv.Itoa(c.Port)
+	}
+
+	params := []struct{ key, value string }{
+		{"host", c.Host},
+		{"port", port},
+		{"user", c.Username},
+		{"password", c.Password},
+		{"dbname", c.DBName},
+		{"sslmode", c.SSLMode},
+		{"sslrootcert", c.SSLRootCert},
+		{"sslcert", c.SSLCert},
+		{"sslkey", c.SSLKey},
+		{"application_name", c.ApplicationName},
+		{"client_encoding", "UTF8"},
+	}
+	if c.StatementTimeout > 0 {
+		// unknown keys are sent to the server as runtime parameters
+		params = append(params, struct{ key, value string }{"statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)})
+	}
+
+	var sb strings.Builder
+	for _, p := range params {
+		if p.value == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(p.key)
+		sb.WriteByte('=')
+		sb.WriteString(quote(p.value))
+	}
+
+	return sb.String(), nil
+}
+
+// quote escapes a value for a keyword/value connection string, which
+// otherwise breaks on passwords containing spaces or quotes.
+func quote(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
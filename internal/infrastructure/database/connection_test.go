@@ -0,0 +1,63 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rosset7i/product_crud/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnString(t *testing.T) {
+	c := &config.ConfDB{
+		Host:             "db.internal",
+		Port:             5432,
+		Username:         "api",
+		Password:         `it's a \secret`,
+		DBName:           "products",
+		SSLMode:          "require",
+		ApplicationName:  "product_crud",
+		StatementTimeout: 5 * time.Second,
+	}
+
+	connString, err := ConnString(c)
+	assert.NoError(t, err)
+
+	parsed, err := pgxpool.ParseConfig(connString)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "db.internal", parsed.ConnConfig.Host)
+	assert.Equal(t, `it's a \secret`, parsed.ConnConfig.Password)
+	assert.Equal(t, "products", parsed.ConnConfig.Database)
+	assert.NotNil(t, parsed.ConnConfig.TLSConfig)
+	assert.Equal(t, "product_crud", parsed.ConnConfig.RuntimeParams["application_name"])
+	assert.Equal(t, "5000", parsed.ConnConfig.RuntimeParams["statement_timeout"])
+}
+
+func TestConnStringTLSFiles(t *testing.T) {
+	connString, err := ConnString(&config.ConfDB{
+		Host:        "db",
+		SSLMode:     "verify-full",
+		SSLRootCert: "/etc/ssl/ca.pem",
+		SSLCert:     "/etc/ssl/client.pem",
+		SSLKey:      "/etc/ssl/client.key",
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, connString, "sslmode=verify-full sslrootcert=/etc/ssl/ca.pem sslcert=/etc/ssl/client.pem sslkey=/etc/ssl/client.key")
+}
+
+func TestConnStringPrefersDSN(t *testing.T) {
+	connString, err := ConnString(&config.ConfDB{DSN: "postgres://api@db/products", Host: "ignored"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres://api@db/products", connString)
+}
+
+func TestConnStringRequiresHost(t *testing.T) {
+	_, err := ConnString(&config.ConfDB{})
+
+	assert.ErrorIs(t, err, errHostIsRequired)
+}
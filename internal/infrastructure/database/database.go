@@ -3,41 +3,104 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/rand/v2"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rosset7i/product_crud/config"
 )
 
-func New(ctx context.Context, c *config.ConfDB) *pgxpool.Pool {
-	config, err := pgxpool.ParseConfig(buildConnectionString(c))
+const pingTimeout = 5 * time.Second
+
+// New opens the pool and waits until the database answers, retrying with
+// backoff until c.ConnectDeadline so the API can start alongside Postgres.
+// Once running, the pool replaces broken connections on its own: idle ones
+// are pinged before use and checked every c.HealthCheckPeriod.
+func New(ctx context.Context, c *config.ConfDB, logger *slog.Logger, tracers ...pgx.QueryTracer) (*pgxpool.Pool, error) {
+	config, err := poolConfig(c, tracers)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, c.ConnectDeadline)
 	defer cancel()
-	if err := pool.Ping(ctxPing); err != nil {
+	err = retry(ctx, c.ConnectBackoff, c.ConnectBackoffMax, func(attempt int) error {
+		ctxPing, cancel := context.WithTimeout(ctx, pingTimeout)
+		defer cancel()
+
+		err := pool.Ping(ctxPing)
+		if err != nil {
+			logger.Warn("database not reachable", "attempt", attempt, "host", c.Host, "err", err)
+			return err
+		}
+		logger.Info("connected to database", "attempt", attempt, "host", c.Host)
+		return nil
+	})
+	if err != nil {
 		pool.Close()
-		log.Fatal(err)
+		return nil, err
 	}
 
-	return pool
+	return pool, nil
 }
 
-func buildConnectionString(c *config.ConfDB) string {
-	return fmt.Sprintf(
-		"dbname=%v user=%v password=%v host=%v port=%v sslmode=disable client_encoding=UTF8",
-		c.DBName,
-		c.Username,
-		c.Password,
-		c.Host,
-		c.Port,
-	)
+// retry calls fn until it succeeds or ctx is done, sleeping between attempts
+// with exponential backoff and jitter so replicas starting together do not
+// hammer the database in lockstep.
+func retry(ctx context.Context, backoff, backoffMax time.Duration, fn func(attempt int) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil {
+			return nil
+		}
+
+		wait := backoff/2 + rand.N(backoff/2+1)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, backoffMax)
+	}
+}
+
+func poolConfig(c *config.ConfDB, tracers []pgx.QueryTracer) (*pgxpool.Config, error) {
+	connString, err := ConnString(c)
+	if err != nil {
+		return nil, err
+	}
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+	if c.MaxConns > 0 {
+		config.MaxConns = c.MaxConns
+	}
+	config.MinConns = c.MinConns
+	if c.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = c.MaxConnIdleTime
+	}
+	switch len(tracers) {
+	case 0:
+	case 1:
+		config.ConnConfig.Tracer = tracers[0]
+	default:
+		config.ConnConfig.Tracer = multitracer.New(tracers...)
+	}
+	if c.HealthCheckPeriod > 0 {
+		config.HealthCheckPeriod = c.HealthCheckPeriod
+	}
+
+	return config, nil
 }
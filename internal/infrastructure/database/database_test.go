@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryUntilSuccess(t *testing.T) {
+	attempts := 0
+	err := retry(context.Background(), time.Millisecond, 4*time.Millisecond, func(attempt int) error {
+		attempts = attempt
+		if attempt < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryGivesUpAtDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	err := retry(ctx, time.Millisecond, 4*time.Millisecond, func(int) error {
+		return errors.New("connection refused")
+	})
+
+	assert.ErrorContains(t, err, "connection refused")
+	assert.ErrorContains(t, err, "giving up after")
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+)
+
+// filterOps are the SQL operators of the plain comparisons; co and sw
+// become ILIKE patterns.
+var filterOps = map[filter.Op]string{
+	filter.Eq: "=",
+	filter.Ne: "<>",
+	filter.Gt: ">",
+	filter.Ge: ">=",
+	filter.Lt: "<",
+	filter.Le: "<=",
+}
+
+// compileFilter turns a parsed filter into a SQL condition. Values are
+// appended to args and referenced by their parameter number, and fields are
+// columns of the same name, the parser having checked them against its
+// whitelist.
+func compileFilter(e filter.Expr, args []any) (string, []any) {
+	switch e := e.(type) {
+	case filter.And:
+		left, args := compileFilter(e.Left, args)
+		right, args := compileFilter(e.Right, args)
+		return "(" + left + " AND " + right + ")", args
+	case filter.Or:
+		left, args := compileFilter(e.Left, args)
+		right, args := compileFilter(e.Right, args)
+		return "(" + left + " OR " + right + ")", args
+	case filter.Not:
+		inner, args := compileFilter(e.Expr, args)
+		return "NOT " + inner, args
+	case filter.Comparison:
+		switch e.Op {
+		case filter.Co:
+			args = append(args, "%"+likeEscaper.Replace(e.Value.(string))+"%")
+			return fmt.Sprintf(`%s ILIKE $%d ESCAPE '\'`, e.Field, len(args)), args
+		case filter.Sw:
+			args = append(args, likeEscaper.Replace(e.Value.(string))+"%")
+			return fmt.Sprintf(`%s ILIKE $%d ESCAPE '\'`, e.Field, len(args)), args
+		}
+		args = append(args, e.Value)
+		return fmt.Sprintf("%s %s $%d", e.Field, filterOps[e.Op], len(args)), args
+	}
+
+	panic(fmt.Sprintf("unexpected filter expression %T", e))
+}
@@ -0,0 +1,18 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileFilter(t *testing.T) {
+	where, err := filter.Parse(`price ge 10 and (name co "50%_off" or not created_at gt 2025-01-01) or name sw "a\\b"`)
+	assert.NoError(t, err)
+
+	condition, args := compileFilter(where, []any{"existing"})
+	assert.Equal(t, `((price >= $2 AND (name ILIKE $3 ESCAPE '\' OR NOT created_at > $4)) OR name ILIKE $5 ESCAPE '\')`, condition)
+	assert.Equal(t, []any{"existing", 10.0, `%50\%\_off%`, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), `a\\b%`}, args)
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/infrastructure/event"
+)
+
+const (
+	notifyChannel      = "product_events"
+	listenBackoff      = 500 * time.Millisecond
+	listenBackoffMax   = 30 * time.Second
+	listenCloseTimeout = 5 * time.Second
+)
+
+var errNotListening = errors.New("not listening for notifications")
+
+// notification is what goes over the channel. Seq comes from the
+// product_event_seq sequence so that every instance numbers the event alike.
+type notification struct {
+	Seq   uint64        `json:"seq"`
+	Event event.Payload `json:"event"`
+}
+
+// Notifier publishes events with pg_notify so that every instance listening
+// on the channel, including this one, sees them.
+type Notifier struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewNotifier(db *pgxpool.Pool, logger *slog.Logger) *Notifier {
+	return &Notifier{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (n *Notifier) Publish(ctx context.Context, e *domain.Event) {
+	payload, err := json.Marshal(event.NewPayload(e))
+	if err != nil {
+		n.logger.ErrorContext(ctx, "could not encode event", "event_id", e.Id, "err", err)
+		return
+	}
+
+	statement := "SELECT pg_notify($1, json_build_object('seq', nextval('product_event_seq'), 'event', $2::json)::text)"
+	if _, err := n.db.Exec(ctx, statement, notifyChannel, string(payload)); err != nil {
+		n.logger.ErrorContext(ctx, "could not notify event", "event_id", e.Id, "err", err)
+	}
+}
+
+// Listener holds a dedicated connection running LISTEN and re-broadcasts the
+// notifications to the local subscriber, reconnecting when the connection
+// drops.
+type Listener struct {
+	connConfig *pgx.ConnConfig
+	subscriber domain.EventPublisher
+	logger     *slog.Logger
+	cancel     context.CancelFunc
+	done       chan struct{}
+	listening  atomic.Bool
+}
+
+func NewListener(db *pgxpool.Pool, subscriber domain.EventPublisher, logger *slog.Logger) *Listener {
+	return &Listener{
+		connConfig: db.Config().ConnConfig.Copy(),
+		subscriber: subscriber,
+		logger:     logger,
+		done:       make(chan struct{}),
+	}
+}
+
+func (l *Listener) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+
+	go func() {
+		defer close(l.done)
+		l.run(ctx)
+	}()
+}
+
+func (l *Listener) Close() {
+	if l.cancel == nil {
+		return
+	}
+	l.cancel()
+	<-l.done
+}
+
+// Check reports the listener as unhealthy while it is not subscribed to the
+// channel, in which case changes made on other instances are missed.
+func (l *Listener) Check(context.Context) error {
+	if !l.listening.Load() {
+		return errNotListening
+	}
+
+	return nil
+}
+
+func (l *Listener) run(ctx context.Context) {
+	backoff := listenBackoff
+	for {
+		err := l.listen(ctx, func() { backoff = listenBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+		l.logger.Warn("listener disconnected", "channel", notifyChannel, "retry_in", backoff, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, listenBackoffMax)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, connected func()) error {
+	conn, err := pgx.ConnectConfig(ctx, l.connConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), listenCloseTimeout)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+	connected()
+	l.listening.Store(true)
+	defer l.listening.Store(false)
+	l.logger.Info("listening for notifications", "channel", notifyChannel)
+
+	for {
+		received, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var n notification
+		if err := json.Unmarshal([]byte(received.Payload), &n); err != nil {
+			l.logger.Warn("dropping malformed notification", "channel", notifyChannel, "err", err)
+			continue
+		}
+		e := n.Event.Event()
+		e.Seq = n.Seq
+		l.subscriber.Publish(ctx, e)
+	}
+}
@@ -3,37 +3,64 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+	"github.com/rosset7i/product_crud/internal/infrastructure/search"
 )
 
+// errNegativePage rejects pages Postgres would not see, like those of a
+// search that is answered without a query.
+var errNegativePage = errors.New("page number and size must not be negative")
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// ProductRepository reads from the replicas of the cluster and writes to
+// its primary. Searches stem with the searchLanguage text search
+// configuration, which must be the one the search column was generated with.
 type ProductRepository struct {
-	db *pgxpool.Pool
+	db             *Cluster
+	searchLanguage string
+	logger         *slog.Logger
 }
 
-func NewProductRepository(db *pgxpool.Pool) *ProductRepository {
+func NewProductRepository(db *Cluster, searchLanguage string, logger *slog.Logger) *ProductRepository {
 	return &ProductRepository{
-		db: db,
+		db:             db,
+		searchLanguage: searchLanguage,
+		logger:         logger,
 	}
 }
 
-func (r *ProductRepository) FetchPaged(ctx context.Context, pageNumber, pageSize int, sort string) ([]*domain.Product, error) {
+func (r *ProductRepository) FetchPaged(ctx context.Context, pageNumber, pageSize int, sort string, where filter.Expr) ([]*domain.Product, error) {
 	if sort != "asc" && sort != "desc" {
 		sort = "asc"
 	}
 
 	offset := (pageNumber - 1) * pageSize
-	rows, err := r.db.Query(
+	statement := "SELECT id, name, price, created_at, updated_at FROM products"
+	var args []any
+	if where != nil {
+		var condition string
+		condition, args = compileFilter(where, args)
+		statement += " WHERE " + condition
+	}
+	args = append(args, pageSize, offset)
+
+	rows, err := conn(ctx, r.db.Reader(ctx)).Query(
 		ctx,
-		`SELECT id, name, price, created_at, updated_at
-		FROM products
-		ORDER BY name `+sort+`
-		LIMIT $1 OFFSET $2`,
-		pageSize, offset,
+		statement+fmt.Sprintf(" ORDER BY name %s LIMIT $%d OFFSET $%d", sort, len(args)-1, len(args)),
+		args...,
 	)
 	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query products", "err", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -52,7 +79,7 @@ func (r *ProductRepository) FetchPaged(ctx context.Context, pageNumber, pageSize
 
 func (r *ProductRepository) FetchById(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
 	var p domain.Product
-	err := r.db.QueryRow(
+	err := conn(ctx, r.db.Reader(ctx)).QueryRow(
 		ctx,
 		`SELECT id, name, price, created_at, updated_at
 		FROM products
@@ -61,13 +88,288 @@ func (r *ProductRepository) FetchById(ctx context.Context, id uuid.UUID) (*domai
 	).Scan(&p.Id, &p.Name, &p.Price, &p.CreatedAt, &p.UpdatedAt)
 
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrProductNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query product", "product_id", id, "err", err)
 		return nil, err
 	}
 	return &p, nil
 }
 
+// FetchByIdForUpdate locks the row with SELECT ... FOR UPDATE, so it only
+// holds back other writers when called within a transaction.
+func (r *ProductRepository) FetchByIdForUpdate(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	var p domain.Product
+	err := conn(ctx, r.db.Writer(ctx)).QueryRow(
+		ctx,
+		`SELECT id, name, price, created_at, updated_at
+		FROM products
+		WHERE id = $1
+		FOR UPDATE`,
+		id,
+	).Scan(&p.Id, &p.Name, &p.Price, &p.CreatedAt, &p.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrProductNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query product for update", "product_id", id, "err", err)
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *ProductRepository) FetchByIds(ctx context.Context, ids []uuid.UUID) ([]*domain.Product, error) {
+	rows, err := conn(ctx, r.db.Reader(ctx)).Query(
+		ctx,
+		`SELECT id, name, price, created_at, updated_at
+		FROM products
+		WHERE id = ANY($1)`,
+		ids,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query products by ids", "count", len(ids), "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]*domain.Product, 0, len(ids))
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(&p.Id, &p.Name, &p.Price, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		products = append(products, &p)
+	}
+
+	return products, rows.Err()
+}
+
+// Search ranks with ts_rank and highlights only the page it returns, as
+// ts_headline has to parse the text again.
+func (r *ProductRepository) Search(ctx context.Context, query string, pageNumber, pageSize int) ([]*domain.ProductMatch, error) {
+	offset := (pageNumber - 1) * pageSize
+	if offset < 0 || pageSize < 0 {
+		return nil, errNegativePage
+	}
+	// a query of exclusions only would match nearly every product, which is
+	// not a useful search result
+	if search.Parse(query).Empty() {
+		return make([]*domain.ProductMatch, 0), nil
+	}
+
+	rows, err := conn(ctx, r.db.Reader(ctx)).Query(
+		ctx,
+		`SELECT id, name, price, created_at, updated_at,
+			ts_headline($1::regconfig, name, query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+		FROM (
+			SELECT id, name, price, created_at, updated_at, query, ts_rank(search, query) AS rank
+			FROM products, websearch_to_tsquery($1::regconfig, $2) AS query
+			WHERE search @@ query
+			ORDER BY rank DESC, name
+			LIMIT $3 OFFSET $4
+		) AS matches
+		ORDER BY rank DESC, name`,
+		r.searchLanguage, query, pageSize, offset,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not search products", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*domain.ProductMatch, 0)
+	for rows.Next() {
+		var m domain.ProductMatch
+		var p domain.Product
+		if err := rows.Scan(&p.Id, &p.Name, &p.Price, &p.CreatedAt, &p.UpdatedAt, &m.Highlight); err != nil {
+			return nil, err
+		}
+		m.Product = &p
+		matches = append(matches, &m)
+	}
+
+	return matches, rows.Err()
+}
+
+// CountByPrice lets width_bucket put every product in its bucket, so only
+// the counts leave the database.
+func (r *ProductRepository) CountByPrice(ctx context.Context, query string, where filter.Expr, bounds domain.PriceBounds) ([]int, error) {
+	counts := make([]int, len(bounds)+1)
+	args := []any{[]float64(bounds)}
+	conditions := []string{"true"}
+	if query != "" {
+		if search.Parse(query).Empty() {
+			return counts, nil
+		}
+		conditions = append(conditions, "search @@ websearch_to_tsquery($2::regconfig, $3)")
+		args = append(args, r.searchLanguage, query)
+	}
+	if where != nil {
+		var condition string
+		condition, args = compileFilter(where, args)
+		conditions = append(conditions, condition)
+	}
+
+	rows, err := conn(ctx, r.db.Reader(ctx)).Query(
+		ctx,
+		"SELECT width_bucket(price, $1::numeric[]), count(*) FROM products WHERE "+strings.Join(conditions, " AND ")+" GROUP BY 1",
+		args...,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not count products by price", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		counts[bucket] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// Suggest unions the names starting with the prefix, found through the
+// prefix index, with the names closest to it by word similarity, found
+// through the trigram index without scanning the table.
+func (r *ProductRepository) Suggest(ctx context.Context, prefix string, limit int) ([]string, error) {
+	lower := strings.ToLower(prefix)
+	rows, err := conn(ctx, r.db.Reader(ctx)).Query(
+		ctx,
+		`SELECT name
+		FROM (
+			(SELECT name, 0::real AS distance
+			FROM products
+			WHERE lower(name) LIKE $1 ESCAPE '\'
+			ORDER BY lower(name)
+			LIMIT $3)
+			UNION ALL
+			(SELECT name, lower(name) <->> $2 AS distance
+			FROM products
+			ORDER BY lower(name) <->> $2
+			LIMIT $3)
+		) AS candidates
+		WHERE distance <= $4
+		GROUP BY name
+		ORDER BY min(distance), name
+		LIMIT $3`,
+		likeEscaper.Replace(lower)+"%", lower, limit, 1-search.MinSimilarity,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not suggest products", "err", err)
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// FetchSimilar walks the trigram index from the closest name outwards,
+// skipping products outside the price range.
+func (r *ProductRepository) FetchSimilar(ctx context.Context, product *domain.Product, criteria domain.DuplicateCriteria, limit int) ([]*domain.Product, error) {
+	low, high := criteria.PriceRange(product.Price)
+	rows, err := conn(ctx, r.db.Reader(ctx)).Query(
+		ctx,
+		`SELECT id, name, price, created_at, updated_at
+		FROM (
+			SELECT id, name, price, created_at, updated_at, lower(name) <-> lower($1::text) AS distance
+			FROM products
+			WHERE id <> $2 AND price BETWEEN $3 AND $4
+			ORDER BY lower(name) <-> lower($1::text)
+			LIMIT $5
+		) AS nearest
+		WHERE distance <= $6
+		ORDER BY distance, name`,
+		product.Name, product.Id, low, high, limit, 1-criteria.NameSimilarity,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query similar products", "product_id", product.Id, "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]*domain.Product, 0)
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(&p.Id, &p.Name, &p.Price, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		products = append(products, &p)
+	}
+
+	return products, rows.Err()
+}
+
+// FetchDuplicatePairs joins the catalog with itself through the trigram
+// index. The % operator reads its threshold from a setting, which is set
+// for this transaction only.
+func (r *ProductRepository) FetchDuplicatePairs(ctx context.Context, criteria domain.DuplicateCriteria, limit int) ([][2]*domain.Product, error) {
+	pairs := make([][2]*domain.Product, 0)
+	err := pgx.BeginFunc(ctx, r.db.Reader(ctx), func(tx pgx.Tx) error {
+		_, err := tx.Exec(
+			ctx,
+			"SELECT set_config('pg_trgm.similarity_threshold', $1, true)",
+			strconv.FormatFloat(criteria.NameSimilarity, 'f', -1, 64),
+		)
+		if err != nil {
+			return err
+		}
+		// the self join is quadratic in the worst case, so the server stops
+		// it at the deadline too instead of relying on the cancel request
+		if deadline, ok := ctx.Deadline(); ok {
+			_, err = tx.Exec(
+				ctx,
+				"SELECT set_config('statement_timeout', $1, true)",
+				strconv.FormatInt(max(time.Until(deadline).Milliseconds(), 1), 10),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		rows, err := tx.Query(
+			ctx,
+			`SELECT a.id, a.name, a.price, a.created_at, a.updated_at,
+				b.id, b.name, b.price, b.created_at, b.updated_at
+			FROM products a
+			JOIN products b ON a.id < b.id AND lower(a.name) % lower(b.name)
+			WHERE abs(a.price - b.price) <= $1 * greatest(a.price, b.price)
+			ORDER BY a.name, b.name
+			LIMIT $2`,
+			criteria.PriceTolerance, limit,
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var a, b domain.Product
+			if err := rows.Scan(
+				&a.Id, &a.Name, &a.Price, &a.CreatedAt, &a.UpdatedAt,
+				&b.Id, &b.Name, &b.Price, &b.CreatedAt, &b.UpdatedAt,
+			); err != nil {
+				return err
+			}
+			pairs = append(pairs, [2]*domain.Product{&a, &b})
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query duplicate products", "err", err)
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
 func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
-	_, err := r.db.Exec(
+	_, err := conn(ctx, r.db.Writer(ctx)).Exec(
 		ctx,
 		"INSERT INTO products (id, name, price, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)",
 		product.Id,
@@ -76,12 +378,15 @@ func (r *ProductRepository) Create(ctx context.Context, product *domain.Product)
 		product.CreatedAt,
 		product.UpdatedAt,
 	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not insert product", "product_id", product.Id, "err", err)
+	}
 
 	return err
 }
 
 func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
-	cmd, err := r.db.Exec(
+	cmd, err := conn(ctx, r.db.Writer(ctx)).Exec(
 		ctx,
 		"UPDATE products SET (name, price, updated_at) = ($1, $2, $3) WHERE id = $4",
 		product.Name,
@@ -90,26 +395,28 @@ func (r *ProductRepository) Update(ctx context.Context, product *domain.Product)
 		product.Id,
 	)
 	if err != nil {
+		r.logger.ErrorContext(ctx, "could not update product", "product_id", product.Id, "err", err)
 		return err
 	}
 	if cmd.RowsAffected() == 0 {
-		return errors.New("product not found")
+		return domain.ErrProductNotFound
 	}
 
 	return err
 }
 
 func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	cmd, err := r.db.Exec(
+	cmd, err := conn(ctx, r.db.Writer(ctx)).Exec(
 		ctx,
 		"DELETE FROM products WHERE id = $1",
 		id,
 	)
 	if err != nil {
+		r.logger.ErrorContext(ctx, "could not delete product", "product_id", id, "err", err)
 		return err
 	}
 	if cmd.RowsAffected() == 0 {
-		return errors.New("product not found")
+		return domain.ErrProductNotFound
 	}
 
 	return nil
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rosset7i/product_crud/config"
+)
+
+const modulePath = "github.com/rosset7i/product_crud/"
+
+const redacted = "[REDACTED]"
+
+// sensitiveColumns mark statements whose string arguments must never reach
+// the logs.
+var sensitiveColumns = []string{"password", "secret"}
+
+// QueryLogger is a pgx.QueryTracer that logs every statement when debug is
+// on, and otherwise only the ones slower than the threshold. A zero
+// threshold turns slow query logging off.
+type QueryLogger struct {
+	logger    *slog.Logger
+	debug     bool
+	threshold time.Duration
+}
+
+func NewQueryLogger(c *config.ConfDB, logger *slog.Logger) *QueryLogger {
+	return &QueryLogger{
+		logger:    logger,
+		debug:     c.Debug,
+		threshold: c.SlowQueryThreshold,
+	}
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	at   time.Time
+	sql  string
+	args []any
+}
+
+func (l *QueryLogger) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !l.debug && l.threshold <= 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, queryStartKey{}, queryStart{at: time.Now(), sql: data.SQL, args: data.Args})
+}
+
+func (l *QueryLogger) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	duration := time.Since(start.at)
+	slow := l.threshold > 0 && duration >= l.threshold
+	if !l.debug && !slow {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("sql", compactSQL(start.sql)),
+		slog.Duration("duration", duration),
+		slog.Int64("rows", data.CommandTag.RowsAffected()),
+		slog.String("caller", callerHint()),
+	}
+	if l.debug {
+		attrs = append(attrs, slog.Any("args", redactArgs(start.sql, start.args)))
+	}
+	if data.Err != nil {
+		attrs = append(attrs, slog.Any("err", data.Err))
+	}
+
+	if slow {
+		l.logger.LogAttrs(ctx, slog.LevelWarn, "slow query", attrs...)
+		return
+	}
+	l.logger.LogAttrs(ctx, slog.LevelInfo, "query", attrs...)
+}
+
+func redactArgs(sql string, args []any) []any {
+	lower := strings.ToLower(sql)
+	sensitive := false
+	for _, column := range sensitiveColumns {
+		if strings.Contains(lower, column) {
+			sensitive = true
+			break
+		}
+	}
+	if !sensitive {
+		return args
+	}
+
+	out := make([]any, len(args))
+	for i, arg := range args {
+		switch arg.(type) {
+		case string, []byte, *string:
+			out[i] = redacted
+		default:
+			out[i] = arg
+		}
+	}
+
+	return out
+}
+
+// callerHint finds the first frame in this module outside of the query
+// logger, which is usually the repository method that ran the statement.
+func callerHint() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, modulePath) && !strings.Contains(frame.Function, "(*QueryLogger)") {
+			name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+			return fmt.Sprintf("%s:%d %s", filepath.Base(frame.File), frame.Line, name)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
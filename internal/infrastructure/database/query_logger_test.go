@@ -0,0 +1,46 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rosset7i/product_crud/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func runQuery(l *QueryLogger, sql string, args []any, sleep time.Duration) {
+	ctx := l.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql, Args: args})
+	time.Sleep(sleep)
+	l.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+}
+
+func TestQueryLoggerDebugLogsEveryStatementRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewQueryLogger(&config.ConfDB{Debug: true}, slog.New(slog.NewTextHandler(&buf, nil)))
+
+	runQuery(l, "SELECT id FROM products WHERE name = $1", []any{"shirt"}, 0)
+	runQuery(l, "INSERT INTO users (email, password_hash) VALUES ($1, $2)", []any{"a@b.c", "hash"}, 0)
+
+	out := buf.String()
+	assert.Contains(t, out, "msg=query")
+	assert.Contains(t, out, "args=[shirt]")
+	assert.Contains(t, out, "args=\"[[REDACTED] [REDACTED]]\"")
+	assert.NotContains(t, out, "hash]")
+	assert.Contains(t, out, "query_logger_test.go:")
+}
+
+func TestQueryLoggerOnlyLogsSlowStatements(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewQueryLogger(&config.ConfDB{SlowQueryThreshold: 20 * time.Millisecond}, slog.New(slog.NewTextHandler(&buf, nil)))
+
+	runQuery(l, "SELECT 1", nil, 0)
+	assert.Empty(t, buf.String())
+
+	runQuery(l, "SELECT id FROM products ORDER BY name", []any{"shirt"}, 25*time.Millisecond)
+	assert.Contains(t, buf.String(), "msg=\"slow query\"")
+	assert.NotContains(t, buf.String(), "args=")
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	txMaxAttempts = 3
+	txBackoff     = 20 * time.Millisecond
+
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// querier is what repositories run statements on: the pool, or the
+// transaction carried by the context.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// commitHooksKey carries the functions AfterCommit defers until the
+// transaction or savepoint they were registered in commits.
+type commitHooksKey struct{}
+
+type commitHooks []func()
+
+// conn returns the transaction started by TxManager.WithinTx, if any, and
+// otherwise the given pool.
+func conn(ctx context.Context, db *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// TxManager implements domain.TxManager on the primary pool.
+type TxManager struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewTxManager(db *pgxpool.Pool, logger *slog.Logger) *TxManager {
+	return &TxManager{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithinTx runs fn in a transaction that every repository call made with the
+// context it receives joins. Inside another WithinTx it runs in a savepoint
+// instead, so only its own work is rolled back on error. An outermost
+// transaction that hits a serialization failure or deadlock is retried, so
+// fn must be safe to run again.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		hooks := &commitHooks{}
+		// Begin on a transaction creates a savepoint
+		err := pgx.BeginFunc(ctx, tx, func(savepoint pgx.Tx) error {
+			return fn(withTx(ctx, savepoint, hooks))
+		})
+		if err != nil {
+			return err
+		}
+		// the savepoint's work now stands or falls with the enclosing one
+		parent := ctx.Value(commitHooksKey{}).(*commitHooks)
+		*parent = append(*parent, *hooks...)
+		return nil
+	}
+
+	backoff := txBackoff
+	for attempt := 1; ; attempt++ {
+		// hooks of an attempt that failed go with its work
+		hooks := &commitHooks{}
+		err := pgx.BeginFunc(ctx, m.db, func(tx pgx.Tx) error {
+			return fn(withTx(ctx, tx, hooks))
+		})
+		if err == nil {
+			for _, hook := range *hooks {
+				hook()
+			}
+			return nil
+		}
+		if !retryable(err) || attempt == txMaxAttempts {
+			return err
+		}
+
+		m.logger.WarnContext(ctx, "retrying transaction", "attempt", attempt, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (m *TxManager) InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return ok
+}
+
+func (m *TxManager) AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		*hooks = append(*hooks, fn)
+		return
+	}
+
+	fn()
+}
+
+func withTx(ctx context.Context, tx pgx.Tx, hooks *commitHooks) context.Context {
+	return context.WithValue(context.WithValue(ctx, txKey{}, tx), commitHooksKey{}, hooks)
+}
+
+// retryable reports serialization failures and deadlocks, which succeed
+// when the transaction is simply run again.
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+}
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, retryable(&pgconn.PgError{Code: "40001"}))
+	assert.True(t, retryable(fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40P01"})))
+	assert.False(t, retryable(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, retryable(errors.New("product not found")))
+}
+
+func TestConnWithoutTransactionUsesPool(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "host=primary")
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	assert.Same(t, pool, conn(context.Background(), pool))
+}
@@ -2,24 +2,30 @@ package database
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rosset7i/product_crud/internal/domain"
 )
 
 type UserRepository struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	logger *slog.Logger
 }
 
-func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+func NewUserRepository(db *pgxpool.Pool, logger *slog.Logger) *UserRepository {
 	return &UserRepository{
-		db: db,
+		db:     db,
+		logger: logger,
 	}
 }
 
 func (r *UserRepository) FetchByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var u domain.User
-	err := r.db.QueryRow(
+	err := conn(ctx, r.db).QueryRow(
 		ctx,
 		`SELECT id, name, email, password_hash, created_at, updated_at
 		FROM users
@@ -27,6 +33,10 @@ func (r *UserRepository) FetchByEmail(ctx context.Context, email string) (*domai
 		email,
 	).Scan(&u.Id, &u.Name, &u.Email, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query user", "err", err)
 		return nil, err
 	}
 
@@ -34,7 +44,7 @@ func (r *UserRepository) FetchByEmail(ctx context.Context, email string) (*domai
 }
 
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
-	_, err := r.db.Exec(
+	_, err := conn(ctx, r.db).Exec(
 		ctx,
 		"INSERT INTO users (id, name, email, password_hash, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)",
 		user.Id,
@@ -44,6 +54,13 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.ErrUserAlreadyExists
+		}
+		r.logger.ErrorContext(ctx, "could not insert user", "user_id", user.Id, "err", err)
+	}
 
 	return err
 }
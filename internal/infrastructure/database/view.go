@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type ViewRepository struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewViewRepository(db *pgxpool.Pool, logger *slog.Logger) *ViewRepository {
+	return &ViewRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ViewRepository) FetchVisible(ctx context.Context, userId uuid.UUID) ([]*domain.View, error) {
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`SELECT id, owner_id, name, filter, sort, columns, shared_with, created_at, updated_at
+		FROM views
+		WHERE owner_id = $1 OR shared_with @> ARRAY[$1::uuid]
+		ORDER BY name, created_at`,
+		userId,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query views", "user_id", userId, "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := make([]*domain.View, 0)
+	for rows.Next() {
+		var v domain.View
+		if err := rows.Scan(&v.Id, &v.OwnerId, &v.Name, &v.Filter, &v.Sort, &v.Columns, &v.SharedWith, &v.CreatedAt, &v.UpdatedAt); err != nil {
+			return nil, err
+		}
+		views = append(views, &v)
+	}
+
+	return views, rows.Err()
+}
+
+func (r *ViewRepository) FetchById(ctx context.Context, id uuid.UUID) (*domain.View, error) {
+	var v domain.View
+	err := conn(ctx, r.db).QueryRow(
+		ctx,
+		`SELECT id, owner_id, name, filter, sort, columns, shared_with, created_at, updated_at
+		FROM views
+		WHERE id = $1`,
+		id,
+	).Scan(&v.Id, &v.OwnerId, &v.Name, &v.Filter, &v.Sort, &v.Columns, &v.SharedWith, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrViewNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query view", "view_id", id, "err", err)
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *ViewRepository) Create(ctx context.Context, view *domain.View) error {
+	_, err := conn(ctx, r.db).Exec(
+		ctx,
+		`INSERT INTO views (id, owner_id, name, filter, sort, columns, shared_with, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		view.Id,
+		view.OwnerId,
+		view.Name,
+		view.Filter,
+		view.Sort,
+		columnsOf(view),
+		sharedWithOf(view),
+		view.CreatedAt,
+		view.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not insert view", "view_id", view.Id, "err", err)
+	}
+
+	return err
+}
+
+func (r *ViewRepository) Update(ctx context.Context, view *domain.View) error {
+	cmd, err := conn(ctx, r.db).Exec(
+		ctx,
+		"UPDATE views SET (name, filter, sort, columns, shared_with, updated_at) = ($1, $2, $3, $4, $5, $6) WHERE id = $7",
+		view.Name,
+		view.Filter,
+		view.Sort,
+		columnsOf(view),
+		sharedWithOf(view),
+		view.UpdatedAt,
+		view.Id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not update view", "view_id", view.Id, "err", err)
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrViewNotFound
+	}
+
+	return nil
+}
+
+func (r *ViewRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	cmd, err := conn(ctx, r.db).Exec(
+		ctx,
+		"DELETE FROM views WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not delete view", "view_id", id, "err", err)
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrViewNotFound
+	}
+
+	return nil
+}
+
+// columnsOf keeps a view without columns from being stored as NULL.
+func columnsOf(view *domain.View) []string {
+	if view.Columns == nil {
+		return []string{}
+	}
+
+	return view.Columns
+}
+
+// sharedWithOf keeps a view shared with nobody from being stored as NULL.
+func sharedWithOf(view *domain.View) []uuid.UUID {
+	if view.SharedWith == nil {
+		return []uuid.UUID{}
+	}
+
+	return view.SharedWith
+}
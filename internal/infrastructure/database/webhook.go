@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type WebhookRepository struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewWebhookRepository(db *pgxpool.Pool, logger *slog.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *WebhookRepository) FetchByOwner(ctx context.Context, ownerId uuid.UUID) ([]*domain.Webhook, error) {
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`SELECT id, owner_id, url, events, secret, created_at, updated_at
+		FROM webhooks
+		WHERE owner_id = $1
+		ORDER BY created_at`,
+		ownerId,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query webhooks", "owner_id", ownerId, "err", err)
+		return nil, err
+	}
+
+	return scanWebhooks(rows)
+}
+
+func (r *WebhookRepository) FetchById(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	var w domain.Webhook
+	var events []string
+	err := conn(ctx, r.db).QueryRow(
+		ctx,
+		`SELECT id, owner_id, url, events, secret, created_at, updated_at
+		FROM webhooks
+		WHERE id = $1`,
+		id,
+	).Scan(&w.Id, &w.OwnerId, &w.Url, &events, &w.Secret, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query webhook", "webhook_id", id, "err", err)
+		return nil, err
+	}
+	w.Events = stringsToEvents(events)
+
+	return &w, nil
+}
+
+func (r *WebhookRepository) FetchByEvent(ctx context.Context, eventType domain.EventType) ([]*domain.Webhook, error) {
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`SELECT id, owner_id, url, events, secret, created_at, updated_at
+		FROM webhooks
+		WHERE $1 = ANY(events)`,
+		string(eventType),
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query webhooks by event", "event_type", eventType, "err", err)
+		return nil, err
+	}
+
+	return scanWebhooks(rows)
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	_, err := conn(ctx, r.db).Exec(
+		ctx,
+		"INSERT INTO webhooks (id, owner_id, url, events, secret, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		webhook.Id,
+		webhook.OwnerId,
+		webhook.Url,
+		eventsToStrings(webhook.Events),
+		webhook.Secret,
+		webhook.CreatedAt,
+		webhook.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not insert webhook", "webhook_id", webhook.Id, "err", err)
+	}
+
+	return err
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	cmd, err := conn(ctx, r.db).Exec(
+		ctx,
+		"UPDATE webhooks SET (url, events, secret, updated_at) = ($1, $2, $3, $4) WHERE id = $5",
+		webhook.Url,
+		eventsToStrings(webhook.Events),
+		webhook.Secret,
+		webhook.UpdatedAt,
+		webhook.Id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not update webhook", "webhook_id", webhook.Id, "err", err)
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	cmd, err := conn(ctx, r.db).Exec(
+		ctx,
+		"DELETE FROM webhooks WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not delete webhook", "webhook_id", id, "err", err)
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func scanWebhooks(rows pgx.Rows) ([]*domain.Webhook, error) {
+	defer rows.Close()
+
+	webhooks := make([]*domain.Webhook, 0)
+	for rows.Next() {
+		var w domain.Webhook
+		var events []string
+		if err := rows.Scan(&w.Id, &w.OwnerId, &w.Url, &events, &w.Secret, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			return nil, err
+		}
+		w.Events = stringsToEvents(events)
+		webhooks = append(webhooks, &w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func eventsToStrings(events []domain.EventType) []string {
+	values := make([]string, len(events))
+	for i, event := range events {
+		values[i] = string(event)
+	}
+
+	return values
+}
+
+func stringsToEvents(values []string) []domain.EventType {
+	events := make([]domain.EventType, len(values))
+	for i, value := range values {
+		events[i] = domain.EventType(value)
+	}
+
+	return events
+}
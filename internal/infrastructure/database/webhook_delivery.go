@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type WebhookDeliveryRepository struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewWebhookDeliveryRepository(db *pgxpool.Pool, logger *slog.Logger) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *WebhookDeliveryRepository) FetchPagedByWebhook(ctx context.Context, webhookId uuid.UUID, pageNumber, pageSize int) ([]*domain.WebhookDelivery, error) {
+	offset := (pageNumber - 1) * pageSize
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`SELECT id, webhook_id, event_id, event_type, payload, status, attempts, response_code, error, delivered_at, next_attempt_at, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`,
+		webhookId, pageSize, offset,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query webhook deliveries", "webhook_id", webhookId, "err", err)
+		return nil, err
+	}
+
+	return scanDeliveries(rows)
+}
+
+func (r *WebhookDeliveryRepository) FetchById(ctx context.Context, id uuid.UUID) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	err := conn(ctx, r.db).QueryRow(
+		ctx,
+		`SELECT id, webhook_id, event_id, event_type, payload, status, attempts, response_code, error, delivered_at, next_attempt_at, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE id = $1`,
+		id,
+	).Scan(
+		&d.Id, &d.WebhookId, &d.EventId, &d.EventType, &d.Payload, &d.Status,
+		&d.Attempts, &d.ResponseCode, &d.Error, &d.DeliveredAt, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query webhook delivery", "delivery_id", id, "err", err)
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+func (r *WebhookDeliveryRepository) FetchPending(ctx context.Context) ([]*domain.WebhookDelivery, error) {
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`SELECT id, webhook_id, event_id, event_type, payload, status, attempts, response_code, error, delivered_at, next_attempt_at, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE status = $1
+		ORDER BY created_at`,
+		string(domain.DeliveryPending),
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query pending webhook deliveries", "err", err)
+		return nil, err
+	}
+
+	return scanDeliveries(rows)
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	_, err := conn(ctx, r.db).Exec(
+		ctx,
+		`INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, attempts, response_code, error, delivered_at, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		delivery.Id,
+		delivery.WebhookId,
+		delivery.EventId,
+		string(delivery.EventType),
+		delivery.Payload,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.Error,
+		delivery.DeliveredAt,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not insert webhook delivery", "delivery_id", delivery.Id, "err", err)
+	}
+
+	return err
+}
+
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	cmd, err := conn(ctx, r.db).Exec(
+		ctx,
+		`UPDATE webhook_deliveries SET (status, attempts, response_code, error, delivered_at, next_attempt_at, updated_at) = ($1, $2, $3, $4, $5, $6, $7)
+		WHERE id = $8`,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.Error,
+		delivery.DeliveredAt,
+		delivery.NextAttemptAt,
+		delivery.UpdatedAt,
+		delivery.Id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not update webhook delivery", "delivery_id", delivery.Id, "err", err)
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) Claim(ctx context.Context, delivery *domain.WebhookDelivery, until time.Time) error {
+	now := time.Now()
+	cmd, err := conn(ctx, r.db).Exec(
+		ctx,
+		`UPDATE webhook_deliveries SET (attempts, next_attempt_at, updated_at) = (attempts + 1, $1, $2)
+		WHERE id = $3 AND status = $4 AND attempts = $5`,
+		until,
+		now,
+		delivery.Id,
+		string(domain.DeliveryPending),
+		delivery.Attempts,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not claim webhook delivery", "delivery_id", delivery.Id, "err", err)
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrWebhookDeliveryClaimed
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = until
+	delivery.UpdatedAt = now
+
+	return nil
+}
+
+func scanDeliveries(rows pgx.Rows) ([]*domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err := rows.Scan(
+			&d.Id, &d.WebhookId, &d.EventId, &d.EventType, &d.Payload, &d.Status,
+			&d.Attempts, &d.ResponseCode, &d.Error, &d.DeliveredAt, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
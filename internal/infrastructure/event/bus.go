@@ -0,0 +1,24 @@
+package event
+
+import (
+	"context"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// Bus forwards every published event to each of its publishers in order.
+type Bus struct {
+	publishers []domain.EventPublisher
+}
+
+func NewBus(publishers ...domain.EventPublisher) *Bus {
+	return &Bus{
+		publishers: publishers,
+	}
+}
+
+func (b *Bus) Publish(ctx context.Context, event *domain.Event) {
+	for _, publisher := range b.publishers {
+		publisher.Publish(ctx, event)
+	}
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// Payload is the wire representation of a domain event shared by every
+// transport that ships events out of the process.
+type Payload struct {
+	Id         uuid.UUID        `json:"id"`
+	Type       domain.EventType `json:"type"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       ProductPayload   `json:"data"`
+}
+
+type ProductPayload struct {
+	Id        uuid.UUID `json:"id"`
+	Name      string    `json:"name,omitzero"`
+	Price     float64   `json:"price,omitzero"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+func NewPayload(event *domain.Event) Payload {
+	p := Payload{
+		Id:         event.Id,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       ProductPayload{Id: event.ProductId},
+	}
+	if event.Product != nil {
+		p.Data.Name = event.Product.Name
+		p.Data.Price = event.Product.Price
+		p.Data.CreatedAt = event.Product.CreatedAt
+		p.Data.UpdatedAt = event.Product.UpdatedAt
+	}
+
+	return p
+}
+
+func (p Payload) Event() *domain.Event {
+	e := &domain.Event{
+		Id:         p.Id,
+		Type:       p.Type,
+		ProductId:  p.Data.Id,
+		OccurredAt: p.OccurredAt,
+	}
+	if p.Type != domain.EventProductDeleted {
+		product := &domain.Product{Name: p.Data.Name, Price: p.Data.Price}
+		product.Id = p.Data.Id
+		product.CreatedAt = p.Data.CreatedAt
+		product.UpdatedAt = p.Data.UpdatedAt
+		e.Product = product
+	}
+
+	return e
+}
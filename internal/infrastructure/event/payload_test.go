@@ -0,0 +1,44 @@
+package event
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayloadRoundTrip(t *testing.T) {
+	product, _ := domain.NewProduct("Product", 10)
+	original := domain.NewProductEvent(domain.EventProductUpdated, product.Id, product)
+
+	raw, err := json.Marshal(NewPayload(original))
+	assert.Nil(t, err)
+
+	var payload Payload
+	assert.Nil(t, json.Unmarshal(raw, &payload))
+	e := payload.Event()
+	assert.Equal(t, original.Id, e.Id)
+	assert.Equal(t, original.Type, e.Type)
+	assert.Equal(t, product.Id, e.ProductId)
+	assert.Equal(t, product.Id, e.Product.Id)
+	assert.Equal(t, "Product", e.Product.Name)
+	assert.Equal(t, float64(10), e.Product.Price)
+	assert.True(t, product.UpdatedAt.Equal(e.Product.UpdatedAt))
+}
+
+func TestPayloadOfDeletionHasNoProduct(t *testing.T) {
+	productId := uuid.New()
+	original := domain.NewProductEvent(domain.EventProductDeleted, productId, nil)
+
+	raw, err := json.Marshal(NewPayload(original))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(raw), "name")
+
+	var payload Payload
+	assert.Nil(t, json.Unmarshal(raw, &payload))
+	e := payload.Event()
+	assert.Equal(t, productId, e.ProductId)
+	assert.Nil(t, e.Product)
+}
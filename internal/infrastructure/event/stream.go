@@ -0,0 +1,177 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type Message struct {
+	Seq   uint64
+	Event *domain.Event
+}
+
+type Filter func(event *domain.Event) bool
+
+// Replay holds the buffered events a new subscriber missed. Complete is false
+// when some of them are no longer known, because they fell out of the buffer
+// or the requested sequence was handed out elsewhere. The subscriber then has
+// to catch up some other way and can resume from Seq.
+type Replay struct {
+	Messages []Message
+	Complete bool
+	Seq      uint64
+}
+
+type Subscription struct {
+	C      <-chan Message
+	c      chan Message
+	filter Filter
+}
+
+// Stream keeps a bounded replay buffer of the latest events and pushes new
+// ones to live subscribers. A subscriber that falls behind is dropped and is
+// expected to reconnect from its last sequence number.
+//
+// Events carrying a Seq keep it, so that every instance fed by the same
+// source hands out the same ids and a client can resume on any of them.
+// Other events are numbered by the stream itself, and those ids only mean
+// something to the instance that handed them out.
+type Stream struct {
+	mu          sync.Mutex
+	seq         uint64
+	shared      bool
+	buffer      []Message
+	next        int
+	size        int
+	clientSize  int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+func NewStream(replaySize, clientBuffer int) *Stream {
+	return &Stream{
+		// sequence numbers start at the boot time so ids handed out before a
+		// restart sort below the new ones and are told to resync
+		seq:         uint64(time.Now().UnixMilli()),
+		buffer:      make([]Message, 0, max(replaySize, 1)),
+		size:        max(replaySize, 1),
+		clientSize:  max(clientBuffer, 1),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (s *Stream) Publish(_ context.Context, event *domain.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	seq := event.Seq
+	switch {
+	case seq == 0:
+		s.seq++
+		seq = s.seq
+	case !s.shared || seq > s.seq:
+		// the first shared sequence number replaces the local one, after
+		// which they can arrive slightly out of order
+		s.shared = true
+		s.seq = seq
+	}
+	m := Message{Seq: seq, Event: event}
+	if len(s.buffer) < s.size {
+		s.buffer = append(s.buffer, m)
+	} else {
+		s.buffer[s.next] = m
+		s.next = (s.next + 1) % s.size
+	}
+
+	for sub := range s.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.c <- m:
+		default:
+			s.drop(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber and returns the buffered events newer than
+// lastSeq that match the filter. A zero lastSeq skips the replay.
+func (s *Stream) Subscribe(lastSeq uint64, filter Filter) (*Subscription, Replay) {
+	c := make(chan Message, s.clientSize)
+	sub := &Subscription{C: c, c: c, filter: filter}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replay := Replay{Complete: true, Seq: s.seq}
+	if s.closed {
+		close(c)
+		return sub, replay
+	}
+	s.subscribers[sub] = struct{}{}
+
+	if lastSeq == 0 {
+		return sub, replay
+	}
+	if !s.covers(lastSeq) {
+		replay.Complete = false
+		return sub, replay
+	}
+
+	replay.Messages = make([]Message, 0)
+	for i := range s.buffer {
+		m := s.buffer[(s.next+i)%len(s.buffer)]
+		if m.Seq <= lastSeq {
+			continue
+		}
+		if filter != nil && !filter(m.Event) {
+			continue
+		}
+		replay.Messages = append(replay.Messages, m)
+	}
+
+	return sub, replay
+}
+
+// covers tells whether the buffer holds every event after lastSeq.
+func (s *Stream) covers(lastSeq uint64) bool {
+	if lastSeq == s.seq {
+		return true
+	}
+	if lastSeq > s.seq || len(s.buffer) == 0 {
+		return false
+	}
+
+	oldest := s.buffer[s.next%len(s.buffer)].Seq
+	return lastSeq >= oldest-1
+}
+
+func (s *Stream) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop(sub)
+}
+
+// Close ends every subscription so that streaming handlers return.
+func (s *Stream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sub := range s.subscribers {
+		s.drop(sub)
+	}
+}
+
+func (s *Stream) drop(sub *Subscription) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+	delete(s.subscribers, sub)
+	close(sub.c)
+}
@@ -0,0 +1,124 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func publish(s *Stream, productId uuid.UUID) {
+	s.Publish(context.Background(), domain.NewProductEvent(domain.EventProductUpdated, productId, nil))
+}
+
+func TestStreamDeliversToSubscribers(t *testing.T) {
+	s := NewStream(10, 10)
+	sub, replay := s.Subscribe(0, nil)
+	assert.Empty(t, replay.Messages)
+	assert.True(t, replay.Complete)
+
+	productId := uuid.New()
+	publish(s, productId)
+
+	m := <-sub.C
+	assert.Equal(t, productId, m.Event.ProductId)
+}
+
+func TestStreamReplaysAfterLastSeq(t *testing.T) {
+	s := NewStream(10, 10)
+	first, _ := s.Subscribe(0, nil)
+	publish(s, uuid.New())
+	publish(s, uuid.New())
+	publish(s, uuid.New())
+
+	seen := <-first.C
+	_, replay := s.Subscribe(seen.Seq, nil)
+	assert.True(t, replay.Complete)
+	assert.Len(t, replay.Messages, 2)
+	assert.Greater(t, replay.Messages[0].Seq, seen.Seq)
+	assert.Greater(t, replay.Messages[1].Seq, replay.Messages[0].Seq)
+}
+
+func TestStreamReplayIsBounded(t *testing.T) {
+	s := NewStream(2, 10)
+	first, _ := s.Subscribe(0, nil)
+	for range 5 {
+		publish(s, uuid.New())
+	}
+	seen := <-first.C
+	last := seen.Seq + 4
+
+	_, replay := s.Subscribe(seen.Seq, nil)
+	assert.False(t, replay.Complete)
+	assert.Empty(t, replay.Messages)
+	assert.Equal(t, last, replay.Seq)
+
+	_, replay = s.Subscribe(last-2, nil)
+	assert.True(t, replay.Complete)
+	assert.Len(t, replay.Messages, 2)
+}
+
+func TestStreamAsksUnknownSeqToResync(t *testing.T) {
+	s := NewStream(10, 10)
+	publish(s, uuid.New())
+
+	_, replay := s.Subscribe(1, nil)
+	assert.False(t, replay.Complete)
+
+	_, replay = s.Subscribe(replay.Seq+1, nil)
+	assert.False(t, replay.Complete)
+}
+
+func TestStreamKeepsSharedSeq(t *testing.T) {
+	s := NewStream(10, 10)
+	for _, seq := range []uint64{7, 9} {
+		e := domain.NewProductEvent(domain.EventProductUpdated, uuid.New(), nil)
+		e.Seq = seq
+		s.Publish(context.Background(), e)
+	}
+
+	_, replay := s.Subscribe(7, nil)
+	assert.True(t, replay.Complete)
+	assert.Len(t, replay.Messages, 1)
+	assert.Equal(t, uint64(9), replay.Messages[0].Seq)
+
+	_, replay = s.Subscribe(5, nil)
+	assert.False(t, replay.Complete)
+}
+
+func TestStreamFiltersEvents(t *testing.T) {
+	s := NewStream(10, 10)
+	wanted := uuid.New()
+	sub, _ := s.Subscribe(0, func(e *domain.Event) bool { return e.ProductId == wanted })
+
+	publish(s, uuid.New())
+	publish(s, wanted)
+
+	m := <-sub.C
+	assert.Equal(t, wanted, m.Event.ProductId)
+	assert.Len(t, sub.C, 0)
+}
+
+func TestStreamDropsSlowSubscribers(t *testing.T) {
+	s := NewStream(10, 1)
+	sub, _ := s.Subscribe(0, nil)
+
+	publish(s, uuid.New())
+	publish(s, uuid.New())
+
+	<-sub.C
+	_, open := <-sub.C
+	assert.False(t, open)
+}
+
+func TestStreamCloseEndsSubscriptions(t *testing.T) {
+	s := NewStream(10, 10)
+	sub, _ := s.Subscribe(0, nil)
+
+	s.Close()
+
+	_, open := <-sub.C
+	assert.False(t, open)
+}
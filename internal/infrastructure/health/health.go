@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOk   = "ok"
+	StatusFail = "fail"
+)
+
+// Check reports a dependency as unhealthy by returning an error.
+type Check func(ctx context.Context) error
+
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type Report struct {
+	Status   string            `json:"status"`
+	Draining bool              `json:"draining,omitempty"`
+	Checks   map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker runs the readiness checks and tracks whether the server started
+// shutting down, in which case it stops reporting ready.
+type Checker struct {
+	timeout  time.Duration
+	names    []string
+	checks   []Check
+	draining atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Register adds a readiness check. It must be called before serving.
+func (c *Checker) Register(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks = append(c.checks, check)
+}
+
+// Drain makes every following readiness report fail so load balancers stop
+// routing new traffic here.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+func (c *Checker) Live() Report {
+	return Report{
+		Status:   StatusOk,
+		Draining: c.draining.Load(),
+	}
+}
+
+// Ready runs every check concurrently, each bounded by the timeout.
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := Report{
+		Status:   StatusOk,
+		Draining: c.draining.Load(),
+		Checks:   make(map[string]Result, len(c.checks)),
+	}
+	if report.Draining {
+		report.Status = StatusFail
+	}
+
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if result.Status != StatusOk {
+			report.Status = StatusFail
+		}
+		report.Checks[c.names[i]] = result
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// a check that ignores its context must not hold the probe
+		err = ctx.Err()
+	}
+	result := Result{
+		Status:   StatusOk,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadyReportsEveryCheck(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Register("database", func(context.Context) error { return nil })
+	c.Register("listener", func(context.Context) error { return errors.New("disconnected") })
+
+	report := c.Ready(context.Background())
+
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusOk, report.Checks["database"].Status)
+	assert.Equal(t, StatusFail, report.Checks["listener"].Status)
+	assert.Equal(t, "disconnected", report.Checks["listener"].Error)
+}
+
+func TestReadyTimesOutSlowChecks(t *testing.T) {
+	c := NewChecker(10 * time.Millisecond)
+	c.Register("stuck", func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	report := c.Ready(context.Background())
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["stuck"].Error)
+}
+
+func TestDrainFailsReadinessButNotLiveness(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Register("database", func(context.Context) error { return nil })
+	assert.Equal(t, StatusOk, c.Ready(context.Background()).Status)
+
+	c.Drain()
+
+	ready := c.Ready(context.Background())
+	assert.Equal(t, StatusFail, ready.Status)
+	assert.True(t, ready.Draining)
+	assert.Equal(t, StatusOk, c.Live().Status)
+}
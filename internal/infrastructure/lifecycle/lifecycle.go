@@ -0,0 +1,95 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Hook is one component of the application. OnStart and OnStop are both
+// optional. Timeout bounds OnStop; zero means no bound.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+	Timeout time.Duration
+}
+
+// Manager starts hooks in the order they were appended and stops them in
+// reverse, so components are torn down only after everything depending on
+// them is gone.
+type Manager struct {
+	hooks   []Hook
+	started int
+	logger  *slog.Logger
+}
+
+func New(logger *slog.Logger) *Manager {
+	return &Manager{logger: logger}
+}
+
+func (m *Manager) Append(hook Hook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+// Start runs every OnStart in order. When one fails, the hooks already
+// started are stopped again before the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	for _, hook := range m.hooks {
+		if hook.OnStart != nil {
+			if err := hook.OnStart(ctx); err != nil {
+				err = fmt.Errorf("start %s: %w", hook.Name, err)
+				return errors.Join(err, m.Stop(ctx))
+			}
+		}
+		m.started++
+	}
+
+	return nil
+}
+
+// Stop runs the OnStop of every started hook in reverse order. A hook that
+// exceeds its timeout is abandoned so the remaining ones still run.
+func (m *Manager) Stop(ctx context.Context) error {
+	var errs []error
+	for ; m.started > 0; m.started-- {
+		hook := m.hooks[m.started-1]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		start := time.Now()
+		m.logger.Info("stopping", "component", hook.Name)
+		if err := stop(ctx, hook); err != nil {
+			m.logger.Error("could not stop", "component", hook.Name, "duration", time.Since(start), "err", err)
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+			continue
+		}
+		m.logger.Info("stopped", "component", hook.Name, "duration", time.Since(start))
+	}
+
+	return errors.Join(errs...)
+}
+
+func stop(ctx context.Context, hook Hook) error {
+	if hook.Timeout <= 0 {
+		return hook.OnStop(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout)
+	defer cancel()
+
+	// some components only offer a blocking Close, so the timeout is
+	// enforced here rather than trusted to the hook
+	done := make(chan error, 1)
+	go func() { done <- hook.OnStop(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
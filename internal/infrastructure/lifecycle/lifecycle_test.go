@@ -0,0 +1,77 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingHook(name string, calls *[]string) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestStopRunsInReverseOrder(t *testing.T) {
+	var calls []string
+	m := New(slog.New(slog.DiscardHandler))
+	m.Append(recordingHook("database", &calls))
+	m.Append(recordingHook("workers", &calls))
+	m.Append(recordingHook("http", &calls))
+
+	assert.NoError(t, m.Start(context.Background()))
+	assert.NoError(t, m.Stop(context.Background()))
+
+	assert.Equal(t, []string{
+		"start database", "start workers", "start http",
+		"stop http", "stop workers", "stop database",
+	}, calls)
+}
+
+func TestStartFailureStopsStartedHooks(t *testing.T) {
+	var calls []string
+	m := New(slog.New(slog.DiscardHandler))
+	m.Append(recordingHook("database", &calls))
+	m.Append(Hook{
+		Name:    "http",
+		OnStart: func(context.Context) error { return errors.New("address in use") },
+		OnStop: func(context.Context) error {
+			calls = append(calls, "stop http")
+			return nil
+		},
+	})
+
+	err := m.Start(context.Background())
+
+	assert.ErrorContains(t, err, "start http: address in use")
+	assert.Equal(t, []string{"start database", "stop database"}, calls)
+}
+
+func TestStopAbandonsHookPastTimeout(t *testing.T) {
+	var calls []string
+	m := New(slog.New(slog.DiscardHandler))
+	m.Append(recordingHook("database", &calls))
+	m.Append(Hook{
+		Name:    "workers",
+		OnStop:  func(context.Context) error { time.Sleep(time.Second); return nil },
+		Timeout: 10 * time.Millisecond,
+	})
+
+	assert.NoError(t, m.Start(context.Background()))
+	err := m.Stop(context.Background())
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"start database", "stop database"}, calls)
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/rosset7i/product_crud/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerAddsRequestFields(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, &config.ConfLog{Level: "info", Format: "json"})
+
+	ctx := WithFields(context.Background())
+	AddFields(ctx, slog.String("request_id", "abc"))
+	AddFields(context.WithValue(ctx, struct{}{}, 1), slog.String("user_id", "42"))
+	logger.InfoContext(ctx, "hello")
+
+	var record map[string]any
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "hello", record["msg"])
+	assert.Equal(t, "abc", record["request_id"])
+	assert.Equal(t, "42", record["user_id"])
+}
+
+func TestLoggerHonoursLevel(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, &config.ConfLog{Level: "warn", Format: "text"})
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	assert.NotContains(t, out.String(), "hidden")
+	assert.Contains(t, out.String(), "msg=shown")
+}
+
+func TestAddFieldsWithoutHolderIsNoop(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, &config.ConfLog{Level: "info", Format: "json"})
+
+	AddFields(context.Background(), slog.String("request_id", "abc"))
+	logger.InfoContext(context.Background(), "hello")
+
+	assert.NotContains(t, out.String(), "request_id")
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+)
+
+// matchFilter evaluates a parsed filter against a product. A nil filter
+// matches everything.
+func matchFilter(e filter.Expr, p *domain.Product) bool {
+	switch e := e.(type) {
+	case nil:
+		return true
+	case filter.And:
+		return matchFilter(e.Left, p) && matchFilter(e.Right, p)
+	case filter.Or:
+		return matchFilter(e.Left, p) || matchFilter(e.Right, p)
+	case filter.Not:
+		return !matchFilter(e.Expr, p)
+	case filter.Comparison:
+		switch e.Field {
+		case filter.FieldId:
+			return compareWith(e.Op, strings.Compare(p.Id.String(), e.Value.(uuid.UUID).String()))
+		case filter.FieldName:
+			name, value := strings.ToLower(p.Name), strings.ToLower(e.Value.(string))
+			switch e.Op {
+			case filter.Co:
+				return strings.Contains(name, value)
+			case filter.Sw:
+				return strings.HasPrefix(name, value)
+			}
+			return compareWith(e.Op, strings.Compare(p.Name, e.Value.(string)))
+		case filter.FieldPrice:
+			return compareWith(e.Op, cmp.Compare(p.Price, e.Value.(float64)))
+		case filter.FieldCreatedAt:
+			return compareWith(e.Op, p.CreatedAt.Compare(e.Value.(time.Time)))
+		case filter.FieldUpdatedAt:
+			return compareWith(e.Op, p.UpdatedAt.Compare(e.Value.(time.Time)))
+		}
+	}
+
+	panic(fmt.Sprintf("unexpected filter expression %T", e))
+}
+
+// compareWith tells whether the result of a three way comparison satisfies
+// op.
+func compareWith(op filter.Op, c int) bool {
+	switch op {
+	case filter.Eq:
+		return c == 0
+	case filter.Ne:
+		return c != 0
+	case filter.Gt:
+		return c > 0
+	case filter.Ge:
+		return c >= 0
+	case filter.Lt:
+		return c < 0
+	case filter.Le:
+		return c <= 0
+	}
+
+	return false
+}
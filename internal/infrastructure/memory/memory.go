@@ -0,0 +1,47 @@
+// Package memory holds thread-safe in-memory implementations of the domain
+// repositories, for running the API without a database. They mirror the
+// Postgres repositories, including their errors, and hand out copies so
+// callers cannot change stored entities behind the repository's back.
+package memory
+
+import (
+	"context"
+	"errors"
+)
+
+var errNegativePage = errors.New("page number and size must not be negative")
+
+// page slices out one page the way LIMIT/OFFSET does.
+func page[T any](items []T, pageNumber, pageSize int) ([]T, error) {
+	offset := (pageNumber - 1) * pageSize
+	if offset < 0 || pageSize < 0 {
+		return nil, errNegativePage
+	}
+	if offset >= len(items) {
+		return make([]T, 0), nil
+	}
+
+	return items[offset:min(offset+pageSize, len(items))], nil
+}
+
+// TxManager runs fn directly. The repositories lock per call, so there is
+// no atomicity across calls, which is acceptable for development use.
+type TxManager struct{}
+
+func NewTxManager() *TxManager {
+	return &TxManager{}
+}
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// InTx is always false, as there is no transaction to take part in.
+func (m *TxManager) InTx(context.Context) bool {
+	return false
+}
+
+// AfterCommit runs fn right away, as every call commits on its own.
+func (m *TxManager) AfterCommit(_ context.Context, fn func()) {
+	fn()
+}
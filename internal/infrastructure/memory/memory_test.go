@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/infrastructure/repotest"
+)
+
+func TestProductRepositoryConformance(t *testing.T) {
+	repotest.ProductRepository(t, func(*testing.T) domain.ProductRepository {
+		return NewProductRepository()
+	})
+}
+
+func TestUserRepositoryConformance(t *testing.T) {
+	repotest.UserRepository(t, func(*testing.T) domain.UserRepository {
+		return NewUserRepository()
+	})
+}
+
+func TestViewRepositoryConformance(t *testing.T) {
+	repotest.ViewRepository(t, func(*testing.T) (domain.UserRepository, domain.ViewRepository) {
+		return NewUserRepository(), NewViewRepository()
+	})
+}
+
+func TestWebhookRepositoryConformance(t *testing.T) {
+	repotest.WebhookRepository(t, func(*testing.T) (domain.UserRepository, domain.WebhookRepository, domain.WebhookDeliveryRepository) {
+		return NewUserRepository(), NewWebhookRepository(), NewWebhookDeliveryRepository()
+	})
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+	"github.com/rosset7i/product_crud/internal/infrastructure/search"
+)
+
+type ProductRepository struct {
+	mu       sync.RWMutex
+	products map[uuid.UUID]domain.Product
+}
+
+func NewProductRepository() *ProductRepository {
+	return &ProductRepository{
+		products: make(map[uuid.UUID]domain.Product),
+	}
+}
+
+// FetchPaged orders by name like the Postgres repository, comparing bytes
+// rather than using a collation.
+func (r *ProductRepository) FetchPaged(_ context.Context, pageNumber, pageSize int, sort string, where filter.Expr) ([]*domain.Product, error) {
+	products := slices.DeleteFunc(r.all(), func(p *domain.Product) bool { return !matchFilter(where, p) })
+
+	slices.SortFunc(products, func(a, b *domain.Product) int {
+		if sort == "desc" {
+			return cmp.Compare(b.Name, a.Name)
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return page(products, pageNumber, pageSize)
+}
+
+func (r *ProductRepository) FetchById(_ context.Context, id uuid.UUID) (*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.products[id]
+	if !ok {
+		return nil, domain.ErrProductNotFound
+	}
+
+	return &p, nil
+}
+
+// FetchByIdForUpdate locks nothing, TxManager giving no atomicity anyway.
+func (r *ProductRepository) FetchByIdForUpdate(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	return r.FetchById(ctx, id)
+}
+
+func (r *ProductRepository) FetchByIds(_ context.Context, ids []uuid.UUID) ([]*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := make([]*domain.Product, 0, len(ids))
+	for _, id := range ids {
+		if p, ok := r.products[id]; ok {
+			products = append(products, &p)
+		}
+	}
+
+	return products, nil
+}
+
+func (r *ProductRepository) FetchSimilar(_ context.Context, product *domain.Product, criteria domain.DuplicateCriteria, limit int) ([]*domain.Product, error) {
+	return search.Similar(product, r.all(), criteria, limit), nil
+}
+
+func (r *ProductRepository) FetchDuplicatePairs(_ context.Context, criteria domain.DuplicateCriteria, limit int) ([][2]*domain.Product, error) {
+	return search.DuplicatePairs(r.all(), criteria, limit), nil
+}
+
+func (r *ProductRepository) Create(_ context.Context, product *domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.products[product.Id] = *product
+
+	return nil
+}
+
+func (r *ProductRepository) Update(_ context.Context, product *domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[product.Id]
+	if !ok {
+		return domain.ErrProductNotFound
+	}
+	stored.Name = product.Name
+	stored.Price = product.Price
+	stored.UpdatedAt = product.UpdatedAt
+	r.products[product.Id] = stored
+
+	return nil
+}
+
+func (r *ProductRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[id]; !ok {
+		return domain.ErrProductNotFound
+	}
+	delete(r.products, id)
+
+	return nil
+}
+
+// all returns copies of every product, in no particular order.
+func (r *ProductRepository) all() []*domain.Product {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := make([]*domain.Product, 0, len(r.products))
+	for _, p := range r.products {
+		products = append(products, &p)
+	}
+
+	return products
+}
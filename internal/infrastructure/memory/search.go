@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+	"github.com/rosset7i/product_crud/internal/infrastructure/search"
+)
+
+// Search matches whole words of the name without stemming, ranking
+// products by how many of their words matched.
+func (r *ProductRepository) Search(_ context.Context, query string, pageNumber, pageSize int) ([]*domain.ProductMatch, error) {
+	q := search.Parse(query)
+	if q.Empty() {
+		return page(make([]*domain.ProductMatch, 0), pageNumber, pageSize)
+	}
+
+	type ranked struct {
+		match *domain.ProductMatch
+		rank  int
+	}
+	var matches []ranked
+	r.mu.RLock()
+	for _, p := range r.products {
+		if hits := matchWords(q, tokenize(p.Name)); hits != nil {
+			matches = append(matches, ranked{
+				match: &domain.ProductMatch{Product: &p, Highlight: highlight(p.Name, hits)},
+				rank:  len(hits),
+			})
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(matches, func(a, b ranked) int {
+		return cmp.Or(cmp.Compare(b.rank, a.rank), cmp.Compare(a.match.Product.Name, b.match.Product.Name))
+	})
+	products := make([]*domain.ProductMatch, len(matches))
+	for i, m := range matches {
+		products[i] = m.match
+	}
+
+	return page(products, pageNumber, pageSize)
+}
+
+func (r *ProductRepository) Suggest(_ context.Context, prefix string, limit int) ([]string, error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.products))
+	for _, p := range r.products {
+		names = append(names, p.Name)
+	}
+	r.mu.RUnlock()
+
+	return search.Suggest(prefix, names, limit), nil
+}
+
+func (r *ProductRepository) CountByPrice(_ context.Context, query string, where filter.Expr, bounds domain.PriceBounds) ([]int, error) {
+	counts := make([]int, len(bounds)+1)
+	q := search.Parse(query)
+	if query != "" && q.Empty() {
+		return counts, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.products {
+		if (query == "" || matchWords(q, tokenize(p.Name)) != nil) && matchFilter(where, &p) {
+			counts[bounds.Bucket(p.Price)]++
+		}
+	}
+
+	return counts, nil
+}
+
+type token struct {
+	word       string
+	start, end int
+}
+
+// tokenize splits text the way search.Words does, keeping where each word
+// is so it can be highlighted.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+// matchWords returns the indexes of the tokens matched by q, or nil when q
+// does not match.
+func matchWords(q search.Query, tokens []token) map[int]bool {
+	for _, phrase := range q.Excluded {
+		if len(phraseAt(phrase, tokens)) > 0 {
+			return nil
+		}
+	}
+
+	hits := make(map[int]bool)
+	for _, clause := range q.Clauses {
+		matched := false
+		for _, phrase := range clause {
+			for _, start := range phraseAt(phrase, tokens) {
+				matched = true
+				for i := range phrase {
+					hits[start+i] = true
+				}
+			}
+		}
+		if !matched {
+			return nil
+		}
+	}
+
+	return hits
+}
+
+// phraseAt returns every token index the phrase starts at.
+func phraseAt(phrase search.Phrase, tokens []token) []int {
+	var starts []int
+	for start := 0; start+len(phrase) <= len(tokens); start++ {
+		matched := true
+		for i, word := range phrase {
+			if tokens[start+i].word != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			starts = append(starts, start)
+		}
+	}
+
+	return starts
+}
+
+func highlight(text string, hits map[int]bool) string {
+	var b strings.Builder
+	b.Grow(len(text) + len(hits)*len("<mark></mark>"))
+	last := 0
+	for i, t := range tokenize(text) {
+		if !hits[i] {
+			continue
+		}
+		b.WriteString(text[last:t.start])
+		b.WriteString("<mark>")
+		b.WriteString(text[t.start:t.end])
+		b.WriteString("</mark>")
+		last = t.end
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
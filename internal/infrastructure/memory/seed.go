@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// Seed is demo data for a server running in memory.
+type Seed struct {
+	Users []struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	} `json:"users"`
+	Products []struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	} `json:"products"`
+}
+
+// LoadSeed decodes a Seed from r and creates its entities through the
+// domain constructors, so seeded data passes the usual validation.
+func LoadSeed(ctx context.Context, r io.Reader, users domain.UserRepository, products domain.ProductRepository) error {
+	var seed Seed
+	if err := json.NewDecoder(r).Decode(&seed); err != nil {
+		return err
+	}
+
+	for _, u := range seed.Users {
+		user, err := domain.NewUser(u.Name, u.Email, u.Password)
+		if err != nil {
+			return err
+		}
+		if err := users.Create(ctx, user); err != nil {
+			return err
+		}
+	}
+	for _, p := range seed.Products {
+		product, err := domain.NewProduct(p.Name, p.Price)
+		if err != nil {
+			return err
+		}
+		if err := products.Create(ctx, product); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSeed(t *testing.T) {
+	f, err := os.Open("../../../cmd/api/seed.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	users := NewUserRepository()
+	products := NewProductRepository()
+
+	assert.NoError(t, LoadSeed(context.Background(), f, users, products))
+
+	user, err := users.FetchByEmail(context.Background(), "demo@example.com")
+	assert.NoError(t, err)
+	assert.True(t, user.ValidatePassword("demo1234"))
+	page, err := products.FetchPaged(context.Background(), 1, 100, "asc", nil)
+	assert.NoError(t, err)
+	assert.Len(t, page, 7)
+}
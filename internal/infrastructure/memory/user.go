@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[string]domain.User // by email, which is unique
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users: make(map[string]domain.User),
+	}
+}
+
+func (r *UserRepository) FetchByEmail(_ context.Context, email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[email]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return &u, nil
+}
+
+func (r *UserRepository) Create(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.Email]; ok {
+		return domain.ErrUserAlreadyExists
+	}
+	r.users[user.Email] = *user
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type ViewRepository struct {
+	mu    sync.RWMutex
+	views map[uuid.UUID]domain.View
+}
+
+func NewViewRepository() *ViewRepository {
+	return &ViewRepository{
+		views: make(map[uuid.UUID]domain.View),
+	}
+}
+
+func (r *ViewRepository) FetchVisible(_ context.Context, userId uuid.UUID) ([]*domain.View, error) {
+	r.mu.RLock()
+	views := make([]*domain.View, 0)
+	for _, v := range r.views {
+		if v.VisibleTo(userId) {
+			views = append(views, copyView(v))
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(views, func(a, b *domain.View) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), a.CreatedAt.Compare(b.CreatedAt))
+	})
+
+	return views, nil
+}
+
+func (r *ViewRepository) FetchById(_ context.Context, id uuid.UUID) (*domain.View, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, ok := r.views[id]
+	if !ok {
+		return nil, domain.ErrViewNotFound
+	}
+
+	return copyView(v), nil
+}
+
+func (r *ViewRepository) Create(_ context.Context, view *domain.View) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.views[view.Id] = *copyView(*view)
+
+	return nil
+}
+
+func (r *ViewRepository) Update(_ context.Context, view *domain.View) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.views[view.Id]
+	if !ok {
+		return domain.ErrViewNotFound
+	}
+	stored.Name = view.Name
+	stored.Filter = view.Filter
+	stored.Sort = view.Sort
+	stored.Columns = slices.Clone(view.Columns)
+	stored.SharedWith = slices.Clone(view.SharedWith)
+	stored.UpdatedAt = view.UpdatedAt
+	r.views[view.Id] = stored
+
+	return nil
+}
+
+func (r *ViewRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.views[id]; !ok {
+		return domain.ErrViewNotFound
+	}
+	delete(r.views, id)
+
+	return nil
+}
+
+func copyView(v domain.View) *domain.View {
+	v.Columns = slices.Clone(v.Columns)
+	v.SharedWith = slices.Clone(v.SharedWith)
+	return &v
+}
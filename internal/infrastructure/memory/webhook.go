@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type WebhookRepository struct {
+	mu       sync.RWMutex
+	webhooks map[uuid.UUID]domain.Webhook
+}
+
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		webhooks: make(map[uuid.UUID]domain.Webhook),
+	}
+}
+
+func (r *WebhookRepository) FetchByOwner(_ context.Context, ownerId uuid.UUID) ([]*domain.Webhook, error) {
+	return r.fetch(func(w *domain.Webhook) bool { return w.OwnerId == ownerId }), nil
+}
+
+func (r *WebhookRepository) FetchById(_ context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	return copyWebhook(w), nil
+}
+
+func (r *WebhookRepository) FetchByEvent(_ context.Context, eventType domain.EventType) ([]*domain.Webhook, error) {
+	return r.fetch(func(w *domain.Webhook) bool { return slices.Contains(w.Events, eventType) }), nil
+}
+
+func (r *WebhookRepository) Create(_ context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.webhooks[webhook.Id] = *copyWebhook(*webhook)
+
+	return nil
+}
+
+func (r *WebhookRepository) Update(_ context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.webhooks[webhook.Id]
+	if !ok {
+		return domain.ErrWebhookNotFound
+	}
+	stored.Url = webhook.Url
+	stored.Events = slices.Clone(webhook.Events)
+	stored.Secret = webhook.Secret
+	stored.UpdatedAt = webhook.UpdatedAt
+	r.webhooks[webhook.Id] = stored
+
+	return nil
+}
+
+func (r *WebhookRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return domain.ErrWebhookNotFound
+	}
+	delete(r.webhooks, id)
+
+	return nil
+}
+
+// fetch returns the matching webhooks oldest first, like the Postgres
+// repository.
+func (r *WebhookRepository) fetch(match func(*domain.Webhook) bool) []*domain.Webhook {
+	r.mu.RLock()
+	webhooks := make([]*domain.Webhook, 0, len(r.webhooks))
+	for _, w := range r.webhooks {
+		if match(&w) {
+			webhooks = append(webhooks, copyWebhook(w))
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(webhooks, func(a, b *domain.Webhook) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return webhooks
+}
+
+func copyWebhook(w domain.Webhook) *domain.Webhook {
+	w.Events = slices.Clone(w.Events)
+	return &w
+}
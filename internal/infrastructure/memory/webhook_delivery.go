@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// WebhookDeliveryRepository keeps deliveries separately from the webhooks,
+// so unlike Postgres deleting a webhook does not cascade to its deliveries.
+type WebhookDeliveryRepository struct {
+	mu         sync.RWMutex
+	deliveries map[uuid.UUID]domain.WebhookDelivery
+}
+
+func NewWebhookDeliveryRepository() *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		deliveries: make(map[uuid.UUID]domain.WebhookDelivery),
+	}
+}
+
+func (r *WebhookDeliveryRepository) FetchPagedByWebhook(_ context.Context, webhookId uuid.UUID, pageNumber, pageSize int) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	for _, d := range r.deliveries {
+		if d.WebhookId == webhookId {
+			deliveries = append(deliveries, copyDelivery(d))
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(deliveries, func(a, b *domain.WebhookDelivery) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return page(deliveries, pageNumber, pageSize)
+}
+
+func (r *WebhookDeliveryRepository) FetchById(_ context.Context, id uuid.UUID) (*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.deliveries[id]
+	if !ok {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+
+	return copyDelivery(d), nil
+}
+
+func (r *WebhookDeliveryRepository) FetchPending(_ context.Context) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	for _, d := range r.deliveries {
+		if d.Status == domain.DeliveryPending {
+			deliveries = append(deliveries, copyDelivery(d))
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(deliveries, func(a, b *domain.WebhookDelivery) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return deliveries, nil
+}
+
+func (r *WebhookDeliveryRepository) Create(_ context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries[delivery.Id] = *copyDelivery(*delivery)
+
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) Update(_ context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.deliveries[delivery.Id]
+	if !ok {
+		return domain.ErrWebhookDeliveryNotFound
+	}
+	updated := copyDelivery(*delivery)
+	stored.Status = updated.Status
+	stored.Attempts = updated.Attempts
+	stored.ResponseCode = updated.ResponseCode
+	stored.Error = updated.Error
+	stored.DeliveredAt = updated.DeliveredAt
+	stored.NextAttemptAt = updated.NextAttemptAt
+	stored.UpdatedAt = updated.UpdatedAt
+	r.deliveries[delivery.Id] = stored
+
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) Claim(_ context.Context, delivery *domain.WebhookDelivery, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.deliveries[delivery.Id]
+	if !ok || stored.Status != domain.DeliveryPending || stored.Attempts != delivery.Attempts {
+		return domain.ErrWebhookDeliveryClaimed
+	}
+	stored.Attempts++
+	stored.NextAttemptAt = until
+	stored.UpdatedAt = time.Now()
+	r.deliveries[delivery.Id] = stored
+
+	delivery.Attempts = stored.Attempts
+	delivery.NextAttemptAt = stored.NextAttemptAt
+	delivery.UpdatedAt = stored.UpdatedAt
+
+	return nil
+}
+
+func copyDelivery(d domain.WebhookDelivery) *domain.WebhookDelivery {
+	d.Payload = slices.Clone(d.Payload)
+	if d.DeliveredAt != nil {
+		deliveredAt := *d.DeliveredAt
+		d.DeliveredAt = &deliveredAt
+	}
+	return &d
+}
@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rosset7i/product_crud/internal/infrastructure/cache"
+	"github.com/rosset7i/product_crud/internal/usecase"
+)
+
+const namespace = "product_crud"
+
+// unmatchedRoute labels requests that did not match any route, so unknown
+// paths cannot blow up the label cardinality.
+const unmatchedRoute = "unmatched"
+
+// Metrics owns a dedicated registry with every collector exposed on /metrics.
+type Metrics struct {
+	registry        *prometheus.Registry
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	useCaseRuns     *prometheus.CounterVec
+	useCaseErrors   *prometheus.CounterVec
+	useCaseDuration *prometheus.HistogramVec
+	logins          *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests served, by route pattern, method and status.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency, by route pattern, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		useCaseRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "usecase",
+			Name:      "executions_total",
+			Help:      "Use case executions, by use case.",
+		}, []string{"usecase"}),
+		useCaseErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "usecase",
+			Name:      "errors_total",
+			Help:      "Use case executions that returned an error, by use case.",
+		}, []string{"usecase"}),
+		useCaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "usecase",
+			Name:      "duration_seconds",
+			Help:      "Use case execution latency, by use case.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"usecase"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "logins_total",
+			Help:      "Login attempts, by result.",
+		}, []string{"result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.useCaseRuns,
+		m.useCaseErrors,
+		m.useCaseDuration,
+		m.logins,
+	)
+	// expose both results from the start so rate() works before the first
+	// failed login
+	m.logins.WithLabelValues("success")
+	m.logins.WithLabelValues("failure")
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware records the count and latency of every request. It labels by
+// chi route pattern rather than path, so it must be mounted on the router.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		labels := prometheus.Labels{"route": route, "method": r.Method, "status": strconv.Itoa(status)}
+		m.httpRequests.With(labels).Inc()
+		m.httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Observe implements usecase.Observer, counting executions and errors.
+func (m *Metrics) Observe(ctx context.Context, name string) (context.Context, func(error)) {
+	start := time.Now()
+
+	return ctx, func(err error) {
+		m.useCaseRuns.WithLabelValues(name).Inc()
+		m.useCaseDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err != nil {
+			m.useCaseErrors.WithLabelValues(name).Inc()
+		}
+	}
+}
+
+// LoginObserver counts successful and failed logins. It is meant to observe
+// the login use case only.
+func (m *Metrics) LoginObserver() usecase.Observer {
+	return loginObserver{logins: m.logins}
+}
+
+// RegisterPool exposes the pool statistics of the given stat source.
+func (m *Metrics) RegisterPool(pool PoolStater) {
+	m.registry.MustRegister(newPoolCollector(pool))
+}
+
+// RegisterCache exposes the product cache counters.
+func (m *Metrics) RegisterCache(stats func() cache.Stats) {
+	m.registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "product_cache",
+			Name:      "hits_total",
+			Help:      "Product lookups served from the cache.",
+		}, func() float64 { return float64(stats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "product_cache",
+			Name:      "misses_total",
+			Help:      "Product lookups that went to the repository.",
+		}, func() float64 { return float64(stats().Misses) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "product_cache",
+			Name:      "entries",
+			Help:      "Products currently held in the cache.",
+		}, func() float64 { return float64(stats().Size) }),
+	)
+}
+
+type loginObserver struct {
+	logins *prometheus.CounterVec
+}
+
+func (o loginObserver) Observe(ctx context.Context, _ string) (context.Context, func(error)) {
+	return ctx, func(err error) {
+		if err != nil {
+			o.logins.WithLabelValues("failure").Inc()
+			return
+		}
+		o.logins.WithLabelValues("success").Inc()
+	}
+}
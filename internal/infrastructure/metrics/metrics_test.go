@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rosset7i/product_crud/internal/infrastructure/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	assert.NoError(t, err)
+
+	return string(body)
+}
+
+func TestMiddlewareLabelsByRoutePattern(t *testing.T) {
+	m := New()
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/v1/products/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/products/abc", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `product_crud_http_requests_total{method="GET",route="/v1/products/{id}",status="404"} 1`)
+	assert.Contains(t, body, `product_crud_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, "/v1/products/abc")
+}
+
+func TestObserveCountsExecutionsAndErrors(t *testing.T) {
+	m := New()
+
+	_, done := m.Observe(context.Background(), "product.create")
+	done(nil)
+	_, done = m.Observe(context.Background(), "product.create")
+	done(errors.New("boom"))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `product_crud_usecase_executions_total{usecase="product.create"} 2`)
+	assert.Contains(t, body, `product_crud_usecase_errors_total{usecase="product.create"} 1`)
+}
+
+func TestLoginObserverCountsResults(t *testing.T) {
+	m := New()
+	observer := m.LoginObserver()
+
+	_, done := observer.Observe(context.Background(), "user.login")
+	done(errors.New("invalid credentials"))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `product_crud_auth_logins_total{result="failure"} 1`)
+	assert.Contains(t, body, `product_crud_auth_logins_total{result="success"} 0`)
+}
+
+func TestRegisterCacheExposesStats(t *testing.T) {
+	m := New()
+	m.RegisterCache(func() cache.Stats { return cache.Stats{Hits: 3, Misses: 2, Size: 1} })
+
+	body := scrape(t, m)
+	assert.Contains(t, body, "product_crud_product_cache_hits_total 3")
+	assert.Contains(t, body, "product_crud_product_cache_misses_total 2")
+	assert.Contains(t, body, "product_crud_product_cache_entries 1")
+}
@@ -0,0 +1,722 @@
+// Package repotest is a conformance suite for the domain repositories.
+// Every implementation runs it, so they stay interchangeable.
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+// ProductRepository runs the suite against repositories created by
+// newRepository, which must return an empty one on every call.
+func ProductRepository(t *testing.T, newRepository func(t *testing.T) domain.ProductRepository) {
+	t.Run("create and fetch by id", func(t *testing.T) {
+		r := newRepository(t)
+		p := newProduct(t, "shirt", 10)
+
+		assert.NoError(t, r.Create(context.Background(), p))
+
+		got, err := r.FetchById(context.Background(), p.Id)
+		assert.NoError(t, err)
+		assertSameProduct(t, p, got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		r := newRepository(t)
+		id := uuid.New()
+
+		_, err := r.FetchById(context.Background(), id)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+		assert.ErrorIs(t, r.Update(context.Background(), newProduct(t, "shirt", 10)), domain.ErrProductNotFound)
+		assert.ErrorIs(t, r.Delete(context.Background(), id), domain.ErrProductNotFound)
+	})
+
+	t.Run("fetch by ids leaves out missing ids", func(t *testing.T) {
+		r := newRepository(t)
+		shirt := newProduct(t, "shirt", 10)
+		mug := newProduct(t, "mug", 5)
+		for _, p := range []*domain.Product{shirt, mug, newProduct(t, "lamp", 20)} {
+			assert.NoError(t, r.Create(context.Background(), p))
+		}
+
+		got, err := r.FetchByIds(context.Background(), []uuid.UUID{mug.Id, uuid.New(), shirt.Id})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"mug", "shirt"}, names(got))
+
+		got, err = r.FetchByIds(context.Background(), []uuid.UUID{uuid.New()})
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		r := newRepository(t)
+		p := newProduct(t, "shirt", 10)
+		assert.NoError(t, r.Create(context.Background(), p))
+
+		p.Name = "t-shirt"
+		p.Price = 12.5
+		p.UpdatedAt = p.UpdatedAt.Add(time.Minute)
+		assert.NoError(t, r.Update(context.Background(), p))
+
+		got, err := r.FetchById(context.Background(), p.Id)
+		assert.NoError(t, err)
+		assertSameProduct(t, p, got)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		r := newRepository(t)
+		p := newProduct(t, "shirt", 10)
+		assert.NoError(t, r.Create(context.Background(), p))
+
+		assert.NoError(t, r.Delete(context.Background(), p.Id))
+
+		_, err := r.FetchById(context.Background(), p.Id)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+
+	t.Run("returned products are copies", func(t *testing.T) {
+		r := newRepository(t)
+		p := newProduct(t, "shirt", 10)
+		assert.NoError(t, r.Create(context.Background(), p))
+		p.Name = "changed after create"
+
+		got, err := r.FetchById(context.Background(), p.Id)
+		assert.NoError(t, err)
+		got.Name = "changed after fetch"
+
+		again, err := r.FetchById(context.Background(), p.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, "shirt", again.Name)
+	})
+
+	t.Run("fetch paged sorts by name and pages", func(t *testing.T) {
+		r := newRepository(t)
+		for _, name := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+			assert.NoError(t, r.Create(context.Background(), newProduct(t, name, 1)))
+		}
+
+		asc, err := r.FetchPaged(context.Background(), 1, 2, "asc", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"alpha", "bravo"}, names(asc))
+
+		second, err := r.FetchPaged(context.Background(), 2, 2, "asc", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"charlie", "delta"}, names(second))
+
+		desc, err := r.FetchPaged(context.Background(), 1, 3, "desc", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"echo", "delta", "charlie"}, names(desc))
+
+		unknownSort, err := r.FetchPaged(context.Background(), 1, 1, "random", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"alpha"}, names(unknownSort))
+
+		beyond, err := r.FetchPaged(context.Background(), 4, 2, "asc", nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, beyond)
+		assert.Empty(t, beyond)
+	})
+
+	t.Run("fetch paged rejects negative pages", func(t *testing.T) {
+		r := newRepository(t)
+
+		_, err := r.FetchPaged(context.Background(), 0, 10, "asc", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("fetch paged filters", func(t *testing.T) {
+		r := newRepository(t)
+		shirt := newProduct(t, "Red Shirt", 9.99)
+		for _, p := range []*domain.Product{
+			shirt,
+			newProduct(t, "Blue shirt", 25),
+			newProduct(t, "Red Scarf", 49.5),
+			newProduct(t, "100% Wool_Coat", 250),
+		} {
+			assert.NoError(t, r.Create(context.Background(), p))
+		}
+
+		tests := []struct {
+			filter   string
+			expected []string
+		}{
+			{`price ge 10`, []string{"100% Wool_Coat", "Blue shirt", "Red Scarf"}},
+			{`price ge 10 and (name co "shirt" or name sw "red")`, []string{"Blue shirt", "Red Scarf"}},
+			{`name eq "Red Shirt" or price gt 100`, []string{"100% Wool_Coat", "Red Shirt"}},
+			{`not name co "shirt"`, []string{"100% Wool_Coat", "Red Scarf"}},
+			{`name co "0% w"`, []string{"100% Wool_Coat"}},
+			{`name co "l_c"`, []string{"100% Wool_Coat"}},
+			{`name co "%"`, []string{"100% Wool_Coat"}},
+			{`price lt 25 or price le 25 and price ne 9.99`, []string{"Blue shirt", "Red Shirt"}},
+			{`id eq ` + shirt.Id.String(), []string{"Red Shirt"}},
+			{`id ne ` + shirt.Id.String(), []string{"100% Wool_Coat", "Blue shirt", "Red Scarf"}},
+			{`created_at gt 2000-01-01 and updated_at lt 2999-01-01T00:00:00Z`, []string{"100% Wool_Coat", "Blue shirt", "Red Scarf", "Red Shirt"}},
+			{`created_at le 2000-01-01`, []string{}},
+		}
+		for _, tt := range tests {
+			where, err := filter.Parse(tt.filter)
+			assert.NoError(t, err, tt.filter)
+			products, err := r.FetchPaged(context.Background(), 1, 10, "asc", where)
+			assert.NoError(t, err, tt.filter)
+			assert.Equal(t, tt.expected, names(products), tt.filter)
+		}
+
+		where, _ := filter.Parse(`name co "shirt"`)
+		counts, err := r.CountByPrice(context.Background(), "", where, domain.PriceBounds{10})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 1}, counts)
+		counts, err = r.CountByPrice(context.Background(), "red", where, domain.PriceBounds{10})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 0}, counts)
+	})
+
+	t.Run("filters compare timestamps as instants", func(t *testing.T) {
+		r := newRepository(t)
+		p := newProduct(t, "Red Shirt", 1)
+		assert.NoError(t, r.Create(context.Background(), p))
+
+		for _, tt := range []struct {
+			filter   string
+			expected []string
+		}{
+			{"created_at lt " + p.CreatedAt.Add(time.Second).In(time.FixedZone("", -5*3600)).Format(time.RFC3339Nano), []string{"Red Shirt"}},
+			{"created_at gt " + p.CreatedAt.Add(-time.Second).In(time.FixedZone("", 9*3600)).Format(time.RFC3339Nano), []string{"Red Shirt"}},
+			{"created_at gt " + p.CreatedAt.Add(time.Second).In(time.FixedZone("", -5*3600)).Format(time.RFC3339Nano), []string{}},
+		} {
+			where, err := filter.Parse(tt.filter)
+			assert.NoError(t, err, tt.filter)
+			products, err := r.FetchPaged(context.Background(), 1, 10, "asc", where)
+			assert.NoError(t, err, tt.filter)
+			assert.Equal(t, tt.expected, names(products), tt.filter)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		r := newRepository(t)
+		for _, name := range []string{"Red Shirt", "Blue Shirt", "Blue Hat", "Green Socks"} {
+			assert.NoError(t, r.Create(context.Background(), newProduct(t, name, 1)))
+		}
+
+		tests := []struct {
+			query    string
+			expected []string
+		}{
+			{query: "shirt", expected: []string{"Blue Shirt", "Red Shirt"}},
+			{query: "BLUE shirt", expected: []string{"Blue Shirt"}},
+			{query: `"blue hat"`, expected: []string{"Blue Hat"}},
+			{query: `"hat blue"`, expected: []string{}},
+			{query: "hat or socks", expected: []string{"Blue Hat", "Green Socks"}},
+			{query: "shirt -red", expected: []string{"Blue Shirt"}},
+			{query: "jacket", expected: []string{}},
+			{query: "-shirt", expected: []string{}},
+		}
+		for _, tt := range tests {
+			matches, err := r.Search(context.Background(), tt.query, 1, 10)
+			assert.NoError(t, err, tt.query)
+			assert.Equal(t, tt.expected, matchNames(matches), tt.query)
+		}
+	})
+
+	t.Run("search highlights and pages", func(t *testing.T) {
+		r := newRepository(t)
+		for _, name := range []string{"Red Shirt", "Blue Shirt", "Blue Hat"} {
+			assert.NoError(t, r.Create(context.Background(), newProduct(t, name, 1)))
+		}
+
+		first, err := r.Search(context.Background(), "shirt", 1, 1)
+		assert.NoError(t, err)
+		if assert.Len(t, first, 1) {
+			assert.Equal(t, "Blue Shirt", first[0].Product.Name)
+			assert.Equal(t, "Blue <mark>Shirt</mark>", first[0].Highlight)
+		}
+
+		second, err := r.Search(context.Background(), "shirt", 2, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Red Shirt"}, matchNames(second))
+
+		_, err = r.Search(context.Background(), "shirt", 0, 10)
+		assert.Error(t, err)
+	})
+
+	t.Run("suggest", func(t *testing.T) {
+		r := newRepository(t)
+		for _, name := range []string{"iPhone Case", "iPhone 15", "Phone Stand", "Coffee Mug", "iPhone 15"} {
+			assert.NoError(t, r.Create(context.Background(), newProduct(t, name, 1)))
+		}
+
+		tests := []struct {
+			prefix   string
+			limit    int
+			expected []string
+		}{
+			{prefix: "iph", limit: 10, expected: []string{"iPhone 15", "iPhone Case"}},
+			{prefix: "IPH", limit: 1, expected: []string{"iPhone 15"}},
+			{prefix: "iphnoe", limit: 10, expected: []string{"iPhone 15", "iPhone Case"}},
+			{prefix: "phone", limit: 10, expected: []string{"Phone Stand", "iPhone 15", "iPhone Case"}},
+			{prefix: "mug", limit: 10, expected: []string{"Coffee Mug"}},
+			{prefix: "laptop", limit: 10, expected: []string{}},
+			{prefix: "100%", limit: 10, expected: []string{}},
+		}
+		for _, tt := range tests {
+			suggestions, err := r.Suggest(context.Background(), tt.prefix, tt.limit)
+			assert.NoError(t, err, tt.prefix)
+			assert.Equal(t, tt.expected, suggestions, tt.prefix)
+		}
+	})
+
+	t.Run("search follows updates and deletes", func(t *testing.T) {
+		r := newRepository(t)
+		p := newProduct(t, "Red Shirt", 1)
+		assert.NoError(t, r.Create(context.Background(), p))
+
+		p.Name = "Red Scarf"
+		assert.NoError(t, r.Update(context.Background(), p))
+		matches, err := r.Search(context.Background(), "shirt", 1, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, matches)
+		matches, err = r.Search(context.Background(), "scarf", 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Red Scarf"}, matchNames(matches))
+
+		assert.NoError(t, r.Delete(context.Background(), p.Id))
+		matches, err = r.Search(context.Background(), "scarf", 1, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("count by price", func(t *testing.T) {
+		r := newRepository(t)
+		for _, p := range []*domain.Product{
+			newProduct(t, "Red Shirt", 9.99),
+			newProduct(t, "Blue Shirt", 10),
+			newProduct(t, "Red Scarf", 49.5),
+			newProduct(t, "Red Coat", 250),
+		} {
+			assert.NoError(t, r.Create(context.Background(), p))
+		}
+		bounds := domain.PriceBounds{10, 50, 100}
+
+		tests := []struct {
+			query    string
+			expected []int
+		}{
+			{"", []int{1, 2, 0, 1}},
+			{"red", []int{1, 1, 0, 1}},
+			{"shirt -blue", []int{1, 0, 0, 0}},
+			{"-red", []int{0, 0, 0, 0}},
+		}
+		for _, tt := range tests {
+			counts, err := r.CountByPrice(context.Background(), tt.query, nil, bounds)
+			assert.NoError(t, err, tt.query)
+			assert.Equal(t, tt.expected, counts, tt.query)
+		}
+	})
+
+	t.Run("fetch similar", func(t *testing.T) {
+		r := newRepository(t)
+		criteria := domain.DuplicateCriteria{NameSimilarity: 0.6, PriceTolerance: 0.1}
+		existing := newProduct(t, "iPhone 15", 1000)
+		for _, p := range []*domain.Product{
+			existing,
+			newProduct(t, "iPhone 15 Pro", 1050),
+			newProduct(t, "iPhone 15", 500),
+			newProduct(t, "Coffee Mug", 1000),
+		} {
+			assert.NoError(t, r.Create(context.Background(), p))
+		}
+
+		similar, err := r.FetchSimilar(context.Background(), newProduct(t, "iphone 15", 990), criteria, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"iPhone 15", "iPhone 15 Pro"}, names(similar))
+		assert.Equal(t, 1000.0, similar[0].Price)
+
+		similar, err = r.FetchSimilar(context.Background(), existing, criteria, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"iPhone 15 Pro"}, names(similar))
+	})
+
+	t.Run("fetch duplicate pairs", func(t *testing.T) {
+		r := newRepository(t)
+		criteria := domain.DuplicateCriteria{NameSimilarity: 0.6, PriceTolerance: 0.1}
+		for _, p := range []*domain.Product{
+			newProduct(t, "iPhone 15", 1000),
+			newProduct(t, "iphone 15", 980),
+			newProduct(t, "iPhone 15", 400),
+			newProduct(t, "Coffee Mug", 10),
+			newProduct(t, "Coffee Mugs", 10.5),
+			newProduct(t, "Desk Lamp", 10),
+		} {
+			assert.NoError(t, r.Create(context.Background(), p))
+		}
+
+		pairs, err := r.FetchDuplicatePairs(context.Background(), criteria, 10)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, [][2]string{
+			{"Coffee Mug", "Coffee Mugs"},
+			{"iPhone 15", "iphone 15"},
+		}, pairNames(pairs))
+
+		pairs, err = r.FetchDuplicatePairs(context.Background(), criteria, 1)
+		assert.NoError(t, err)
+		assert.Len(t, pairs, 1)
+	})
+}
+
+// ProductUpdatesWithinTx checks that transactions reading a product with
+// FetchByIdForUpdate before writing it do not lose each other's update.
+// newRepositories must return an empty product repository and the
+// transaction manager of its storage.
+func ProductUpdatesWithinTx(t *testing.T, newRepositories func(t *testing.T) (domain.ProductRepository, domain.TxManager)) {
+	products, txManager := newRepositories(t)
+	p := newProduct(t, "shirt", 10)
+	assert.NoError(t, products.Create(context.Background(), p))
+
+	increment := func(locked chan<- struct{}) error {
+		return txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+			got, err := products.FetchByIdForUpdate(ctx, p.Id)
+			if err != nil {
+				return err
+			}
+			if locked != nil {
+				close(locked)
+				// leave the other transaction time to read the product
+				time.Sleep(50 * time.Millisecond)
+			}
+			got.Price++
+			return products.Update(ctx, got)
+		})
+	}
+
+	locked := make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- increment(locked) }()
+	select {
+	case <-locked:
+	case err := <-done:
+		t.Fatalf("first update failed before locking: %v", err)
+	}
+
+	assert.NoError(t, increment(nil))
+	assert.NoError(t, <-done)
+
+	got, err := products.FetchById(context.Background(), p.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 12.0, got.Price)
+}
+
+// UserRepository runs the suite against repositories created by
+// newRepository, which must return an empty one on every call.
+func UserRepository(t *testing.T, newRepository func(t *testing.T) domain.UserRepository) {
+	t.Run("create and fetch by email", func(t *testing.T) {
+		r := newRepository(t)
+		u := newUser(t, "ada@example.com")
+
+		assert.NoError(t, r.Create(context.Background(), u))
+
+		got, err := r.FetchByEmail(context.Background(), u.Email)
+		assert.NoError(t, err)
+		assert.Equal(t, u.Id, got.Id)
+		assert.Equal(t, u.Name, got.Name)
+		assert.Equal(t, u.PasswordHash, got.PasswordHash)
+		assert.WithinDuration(t, u.CreatedAt, got.CreatedAt, time.Millisecond)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		r := newRepository(t)
+
+		_, err := r.FetchByEmail(context.Background(), "nobody@example.com")
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("email is unique", func(t *testing.T) {
+		r := newRepository(t)
+		assert.NoError(t, r.Create(context.Background(), newUser(t, "ada@example.com")))
+
+		err := r.Create(context.Background(), newUser(t, "ada@example.com"))
+		assert.ErrorIs(t, err, domain.ErrUserAlreadyExists)
+	})
+}
+
+// ViewRepository runs the suite against repositories created by
+// newRepositories, which must return empty ones on every call. Views belong
+// to users, so the user repository has to share their storage.
+func ViewRepository(t *testing.T, newRepositories func(t *testing.T) (domain.UserRepository, domain.ViewRepository)) {
+	t.Run("create, fetch, update and delete", func(t *testing.T) {
+		users, r := newRepositories(t)
+		owner := newUser(t, "ada@example.com")
+		assert.NoError(t, users.Create(context.Background(), owner))
+		v := newView(t, owner.Id, "Cheap shirts", []string{"name", "price"}, nil)
+
+		assert.NoError(t, r.Create(context.Background(), v))
+		got, err := r.FetchById(context.Background(), v.Id)
+		assert.NoError(t, err)
+		assertSameView(t, v, got)
+
+		v.Name = "Cheap scarves"
+		v.Filter = `name co "scarf"`
+		v.Sort = "desc"
+		v.Columns = nil
+		v.SharedWith = []uuid.UUID{uuid.New(), uuid.New()}
+		v.UpdatedAt = v.UpdatedAt.Add(time.Minute)
+		assert.NoError(t, r.Update(context.Background(), v))
+		got, err = r.FetchById(context.Background(), v.Id)
+		assert.NoError(t, err)
+		assertSameView(t, v, got)
+
+		assert.NoError(t, r.Delete(context.Background(), v.Id))
+		_, err = r.FetchById(context.Background(), v.Id)
+		assert.ErrorIs(t, err, domain.ErrViewNotFound)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, r := newRepositories(t)
+		missing := newView(t, uuid.New(), "Missing", nil, nil)
+
+		_, err := r.FetchById(context.Background(), missing.Id)
+		assert.ErrorIs(t, err, domain.ErrViewNotFound)
+		assert.ErrorIs(t, r.Update(context.Background(), missing), domain.ErrViewNotFound)
+		assert.ErrorIs(t, r.Delete(context.Background(), missing.Id), domain.ErrViewNotFound)
+	})
+
+	t.Run("fetch visible returns owned views and views shared with the user by name", func(t *testing.T) {
+		users, r := newRepositories(t)
+		ada, grace, alan := newUser(t, "ada@example.com"), newUser(t, "grace@example.com"), newUser(t, "alan@example.com")
+		for _, u := range []*domain.User{ada, grace, alan} {
+			assert.NoError(t, users.Create(context.Background(), u))
+		}
+		for _, v := range []*domain.View{
+			newView(t, ada.Id, "Ada private", nil, nil),
+			newView(t, ada.Id, "Ada shared with Grace", nil, []uuid.UUID{grace.Id}),
+			newView(t, grace.Id, "Grace private", nil, nil),
+			newView(t, grace.Id, "Grace shared with Ada", nil, []uuid.UUID{alan.Id, ada.Id}),
+			newView(t, grace.Id, "Grace shared with Alan", nil, []uuid.UUID{alan.Id}),
+		} {
+			assert.NoError(t, r.Create(context.Background(), v))
+		}
+
+		visible, err := r.FetchVisible(context.Background(), ada.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Ada private", "Ada shared with Grace", "Grace shared with Ada"}, viewNames(visible))
+
+		visible, err = r.FetchVisible(context.Background(), alan.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Grace shared with Ada", "Grace shared with Alan"}, viewNames(visible))
+
+		visible, err = r.FetchVisible(context.Background(), uuid.New())
+		assert.NoError(t, err)
+		assert.Empty(t, visible)
+	})
+}
+
+// WebhookRepository runs the suite against repositories created by
+// newRepositories, which must return empty ones on every call. Webhooks
+// belong to users and deliveries to webhooks, so all three repositories
+// have to share their storage.
+func WebhookRepository(t *testing.T, newRepositories func(t *testing.T) (domain.UserRepository, domain.WebhookRepository, domain.WebhookDeliveryRepository)) {
+	t.Run("create, fetch, update and delete", func(t *testing.T) {
+		users, r, _ := newRepositories(t)
+		owner := newUser(t, "ada@example.com")
+		assert.NoError(t, users.Create(context.Background(), owner))
+		w := newWebhook(t, owner.Id, "https://example.com/created")
+
+		assert.NoError(t, r.Create(context.Background(), w))
+		got, err := r.FetchById(context.Background(), w.Id)
+		assert.NoError(t, err)
+		assertSameWebhook(t, w, got)
+
+		w.Url = "https://example.com/updated"
+		w.Events = []domain.EventType{domain.EventProductDeleted}
+		w.UpdatedAt = w.UpdatedAt.Add(time.Minute)
+		assert.NoError(t, r.Update(context.Background(), w))
+		got, err = r.FetchById(context.Background(), w.Id)
+		assert.NoError(t, err)
+		assertSameWebhook(t, w, got)
+
+		assert.NoError(t, r.Delete(context.Background(), w.Id))
+		_, err = r.FetchById(context.Background(), w.Id)
+		assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+	})
+
+	t.Run("fetch by owner leaves out other users webhooks", func(t *testing.T) {
+		users, r, _ := newRepositories(t)
+		ada, grace := newUser(t, "ada@example.com"), newUser(t, "grace@example.com")
+		assert.NoError(t, users.Create(context.Background(), ada))
+		assert.NoError(t, users.Create(context.Background(), grace))
+		first := newWebhook(t, ada.Id, "https://example.com/first")
+		second := newWebhook(t, ada.Id, "https://example.com/second")
+		second.CreatedAt = first.CreatedAt.Add(time.Second)
+		for _, w := range []*domain.Webhook{second, first, newWebhook(t, grace.Id, "https://example.com/grace")} {
+			assert.NoError(t, r.Create(context.Background(), w))
+		}
+
+		owned, err := r.FetchByOwner(context.Background(), ada.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https://example.com/first", "https://example.com/second"}, webhookUrls(owned))
+
+		owned, err = r.FetchByOwner(context.Background(), uuid.New())
+		assert.NoError(t, err)
+		assert.NotNil(t, owned)
+		assert.Empty(t, owned)
+	})
+
+	t.Run("fetch pending and claim deliveries", func(t *testing.T) {
+		users, r, deliveries := newRepositories(t)
+		owner := newUser(t, "ada@example.com")
+		assert.NoError(t, users.Create(context.Background(), owner))
+		w := newWebhook(t, owner.Id, "https://example.com/hook")
+		assert.NoError(t, r.Create(context.Background(), w))
+		pending := domain.NewWebhookDelivery(w.Id, uuid.New(), domain.EventProductCreated, []byte(`{}`))
+		delivered := domain.NewWebhookDelivery(w.Id, uuid.New(), domain.EventProductCreated, []byte(`{}`))
+		delivered.Status = domain.DeliverySucceeded
+		for _, d := range []*domain.WebhookDelivery{pending, delivered} {
+			assert.NoError(t, deliveries.Create(context.Background(), d))
+		}
+
+		got, err := deliveries.FetchPending(context.Background())
+		assert.NoError(t, err)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, pending.Id, got[0].Id)
+			assert.WithinDuration(t, pending.NextAttemptAt, got[0].NextAttemptAt, time.Millisecond)
+		}
+
+		// two instances resuming the same delivery: only the first claim wins
+		stale := *got[0]
+		until := time.Now().Add(time.Minute)
+		assert.NoError(t, deliveries.Claim(context.Background(), got[0], until))
+		assert.Equal(t, 1, got[0].Attempts)
+		assert.ErrorIs(t, deliveries.Claim(context.Background(), &stale, until), domain.ErrWebhookDeliveryClaimed)
+		assert.ErrorIs(t, deliveries.Claim(context.Background(), delivered, until), domain.ErrWebhookDeliveryClaimed)
+
+		claimed, err := deliveries.FetchById(context.Background(), pending.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, claimed.Attempts)
+		assert.WithinDuration(t, until, claimed.NextAttemptAt, time.Millisecond)
+
+		retryAt := time.Now().Add(time.Hour)
+		claimed.NextAttemptAt = retryAt
+		assert.NoError(t, deliveries.Update(context.Background(), claimed))
+		again, err := deliveries.FetchById(context.Background(), pending.Id)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, retryAt, again.NextAttemptAt, time.Millisecond)
+	})
+}
+
+func newWebhook(t *testing.T, ownerId uuid.UUID, url string) *domain.Webhook {
+	t.Helper()
+	w, err := domain.NewWebhook(ownerId, url, []domain.EventType{domain.EventProductCreated}, "s3cr3t")
+	assert.NoError(t, err)
+
+	return w
+}
+
+func assertSameWebhook(t *testing.T, expected, actual *domain.Webhook) {
+	t.Helper()
+	assert.Equal(t, expected.Id, actual.Id)
+	assert.Equal(t, expected.OwnerId, actual.OwnerId)
+	assert.Equal(t, expected.Url, actual.Url)
+	assert.Equal(t, expected.Events, actual.Events)
+	assert.Equal(t, expected.Secret, actual.Secret)
+	assert.WithinDuration(t, expected.CreatedAt, actual.CreatedAt, time.Millisecond)
+	assert.WithinDuration(t, expected.UpdatedAt, actual.UpdatedAt, time.Millisecond)
+}
+
+func webhookUrls(webhooks []*domain.Webhook) []string {
+	out := make([]string, len(webhooks))
+	for i, w := range webhooks {
+		out[i] = w.Url
+	}
+
+	return out
+}
+
+func newView(t *testing.T, ownerId uuid.UUID, name string, columns []string, sharedWith []uuid.UUID) *domain.View {
+	t.Helper()
+	v, err := domain.NewView(ownerId, name, "price ge 10", "", columns, sharedWith)
+	assert.NoError(t, err)
+
+	return v
+}
+
+func assertSameView(t *testing.T, expected, actual *domain.View) {
+	t.Helper()
+	assert.Equal(t, expected.Id, actual.Id)
+	assert.Equal(t, expected.OwnerId, actual.OwnerId)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Filter, actual.Filter)
+	assert.Equal(t, expected.Sort, actual.Sort)
+	assert.ElementsMatch(t, expected.Columns, actual.Columns)
+	assert.ElementsMatch(t, expected.SharedWith, actual.SharedWith)
+	assert.WithinDuration(t, expected.CreatedAt, actual.CreatedAt, time.Millisecond)
+	assert.WithinDuration(t, expected.UpdatedAt, actual.UpdatedAt, time.Millisecond)
+}
+
+func viewNames(views []*domain.View) []string {
+	out := make([]string, len(views))
+	for i, v := range views {
+		out[i] = v.Name
+	}
+
+	return out
+}
+
+func newProduct(t *testing.T, name string, price float64) *domain.Product {
+	t.Helper()
+	p, err := domain.NewProduct(name, price)
+	assert.NoError(t, err)
+
+	return p
+}
+
+func newUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+	u, err := domain.NewUser("Ada", email, "secret")
+	assert.NoError(t, err)
+
+	return u
+}
+
+// assertSameProduct allows for databases storing timestamps with less
+// precision than Go.
+func assertSameProduct(t *testing.T, expected, actual *domain.Product) {
+	t.Helper()
+	assert.Equal(t, expected.Id, actual.Id)
+	assert.Equal(t, expected.Name, actual.Name)
+	assert.Equal(t, expected.Price, actual.Price)
+	assert.WithinDuration(t, expected.CreatedAt, actual.CreatedAt, time.Millisecond)
+	assert.WithinDuration(t, expected.UpdatedAt, actual.UpdatedAt, time.Millisecond)
+}
+
+func matchNames(matches []*domain.ProductMatch) []string {
+	out := make([]string, len(matches))
+	for i, m := range matches {
+		out[i] = m.Product.Name
+	}
+
+	return out
+}
+
+func names(products []*domain.Product) []string {
+	out := make([]string, len(products))
+	for i, p := range products {
+		out[i] = p.Name
+	}
+
+	return out
+}
+
+// pairNames lists the names of each pair sorted, since backends do not agree
+// on which product of a pair comes first.
+func pairNames(pairs [][2]*domain.Product) [][2]string {
+	out := make([][2]string, len(pairs))
+	for i, p := range pairs {
+		out[i] = [2]string{min(p[0].Name, p[1].Name), max(p[0].Name, p[1].Name)}
+	}
+
+	return out
+}
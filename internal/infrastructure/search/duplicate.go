@@ -0,0 +1,91 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// Similarity scores from 0 to 1 how alike two texts are the way pg_trgm's
+// similarity does: the trigrams they share over all their trigrams.
+func Similarity(a, b string) float64 {
+	setA, setB := trigramSet(a), trigramSet(b)
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range setA {
+		if setB[t] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(setA)+len(setB)-shared)
+}
+
+func trigramSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, t := range trigrams(text) {
+		set[t] = true
+	}
+
+	return set
+}
+
+// Similar picks up to limit candidates that are likely duplicates of
+// product, most similar name first. The product itself is skipped.
+func Similar(product *domain.Product, candidates []*domain.Product, c domain.DuplicateCriteria, limit int) []*domain.Product {
+	type scored struct {
+		product    *domain.Product
+		similarity float64
+	}
+	var matches []scored
+	for _, candidate := range candidates {
+		if candidate.Id == product.Id || !c.ClosePrices(product.Price, candidate.Price) {
+			continue
+		}
+		if similarity := Similarity(product.Name, candidate.Name); similarity >= c.NameSimilarity {
+			matches = append(matches, scored{product: candidate, similarity: similarity})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b scored) int {
+		return cmp.Or(cmp.Compare(b.similarity, a.similarity), cmp.Compare(a.product.Name, b.product.Name))
+	})
+	similar := make([]*domain.Product, 0, min(limit, len(matches)))
+	for _, m := range matches[:min(limit, len(matches))] {
+		similar = append(similar, m.product)
+	}
+
+	return similar
+}
+
+// DuplicatePairs finds up to limit pairs of likely duplicates among
+// products. Sorting by price first means each product is only compared with
+// the ones in its price range.
+func DuplicatePairs(products []*domain.Product, c domain.DuplicateCriteria, limit int) [][2]*domain.Product {
+	sorted := slices.Clone(products)
+	slices.SortFunc(sorted, func(a, b *domain.Product) int {
+		return cmp.Or(cmp.Compare(a.Price, b.Price), cmp.Compare(a.Name, b.Name))
+	})
+
+	pairs := make([][2]*domain.Product, 0)
+	for i, a := range sorted {
+		for _, b := range sorted[i+1:] {
+			if !c.ClosePrices(a.Price, b.Price) {
+				break
+			}
+			if Similarity(a.Name, b.Name) < c.NameSimilarity {
+				continue
+			}
+			if len(pairs) == limit {
+				return pairs
+			}
+			pairs = append(pairs, [2]*domain.Product{a, b})
+		}
+	}
+
+	return pairs
+}
@@ -0,0 +1,53 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("iPhone 15", "iphone 15"))
+	assert.Greater(t, Similarity("iPhone 15 Pro", "iPhone 15"), 0.6)
+	assert.Less(t, Similarity("Coffee Mug", "iPhone 15"), 0.1)
+	assert.Equal(t, 0.0, Similarity("", "iPhone 15"))
+}
+
+func TestSimilar(t *testing.T) {
+	c := domain.DuplicateCriteria{NameSimilarity: 0.6, PriceTolerance: 0.1}
+	product := &domain.Product{Name: "iPhone 15", Price: 1000}
+	product.Id = uuid.New()
+	candidates := []*domain.Product{
+		{Name: "iPhone 15", Price: 950},
+		{Name: "iPhone 15 Pro", Price: 1050},
+		{Name: "iPhone 15", Price: 500},
+		{Name: "Coffee Mug", Price: 1000},
+	}
+	for _, c := range candidates {
+		c.Id = uuid.New()
+	}
+
+	similar := Similar(product, append(candidates, product), c, 10)
+	assert.Equal(t, []*domain.Product{candidates[0], candidates[1]}, similar)
+	assert.Equal(t, []*domain.Product{candidates[0]}, Similar(product, candidates, c, 1))
+}
+
+func TestDuplicatePairs(t *testing.T) {
+	c := domain.DuplicateCriteria{NameSimilarity: 0.6, PriceTolerance: 0.1}
+	products := []*domain.Product{
+		{Name: "iPhone 15", Price: 1000},
+		{Name: "Coffee Mug", Price: 10},
+		{Name: "iphone 15", Price: 980},
+		{Name: "Coffee Mugs", Price: 10.5},
+		{Name: "iPhone 15", Price: 400},
+	}
+
+	pairs := DuplicatePairs(products, c, 10)
+	assert.Equal(t, [][2]*domain.Product{
+		{products[1], products[3]},
+		{products[2], products[0]},
+	}, pairs)
+	assert.Len(t, DuplicatePairs(products, c, 1), 1)
+}
@@ -0,0 +1,102 @@
+// Package search parses the web search syntax accepted by Postgres'
+// websearch_to_tsquery, so the backends without it understand the same
+// queries: words must all match, "quoted text" matches as a phrase, "or"
+// accepts either neighbour and a leading "-" excludes a word or phrase.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Phrase is a sequence of lowercase words that must appear next to each
+// other.
+type Phrase []string
+
+// Query is a parsed search. Every clause must match, and a clause matches
+// when any of its phrases does. No excluded phrase may match.
+type Query struct {
+	Clauses  [][]Phrase
+	Excluded []Phrase
+}
+
+// Empty reports a query that cannot match anything, like one made of
+// punctuation or exclusions only.
+func (q Query) Empty() bool {
+	return len(q.Clauses) == 0
+}
+
+// Parse never fails: like websearch_to_tsquery it ignores syntax it does
+// not understand, such as an unterminated quote or a dangling "or".
+func Parse(input string) Query {
+	var (
+		q       Query
+		negate  bool
+		or      bool
+		runes   = []rune(input)
+		i       int
+		phrase  Phrase
+		literal bool
+	)
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '-' && !negate:
+			negate = true
+			i++
+			continue
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			phrase, literal = Words(string(runes[i+1:min(end, len(runes))])), true
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			phrase, literal = Words(string(runes[i:end])), false
+			i = end
+		}
+
+		if !literal && !negate && len(phrase) == 1 && phrase[0] == "or" {
+			or = len(q.Clauses) > 0
+			continue
+		}
+		if len(phrase) == 0 {
+			negate = false
+			continue
+		}
+
+		switch {
+		case negate:
+			q.Excluded = append(q.Excluded, phrase)
+		case or:
+			last := len(q.Clauses) - 1
+			q.Clauses[last] = append(q.Clauses[last], phrase)
+		default:
+			q.Clauses = append(q.Clauses, []Phrase{phrase})
+		}
+		negate, or = false, false
+	}
+
+	return q
+}
+
+// Words splits text into lowercase words of letters and digits, the way
+// the search backends tokenize it.
+func Words(text string) Phrase {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return fields
+}
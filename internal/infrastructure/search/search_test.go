@@ -0,0 +1,61 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Query
+	}{
+		{
+			input:    "blue shirt",
+			expected: Query{Clauses: [][]Phrase{{{"blue"}}, {{"shirt"}}}},
+		},
+		{
+			input:    `"blue shirt" cotton`,
+			expected: Query{Clauses: [][]Phrase{{{"blue", "shirt"}}, {{"cotton"}}}},
+		},
+		{
+			input:    "shirt or hat socks",
+			expected: Query{Clauses: [][]Phrase{{{"shirt"}, {"hat"}}, {{"socks"}}}},
+		},
+		{
+			input: `shirt -red -"dark blue"`,
+			expected: Query{
+				Clauses:  [][]Phrase{{{"shirt"}}},
+				Excluded: []Phrase{{"red"}, {"dark", "blue"}},
+			},
+		},
+		{
+			input:    "T-Shirt",
+			expected: Query{Clauses: [][]Phrase{{{"t", "shirt"}}}},
+		},
+		{
+			input:    `or shirt "or"`,
+			expected: Query{Clauses: [][]Phrase{{{"shirt"}}, {{"or"}}}},
+		},
+		{
+			input:    `"unterminated phrase`,
+			expected: Query{Clauses: [][]Phrase{{{"unterminated", "phrase"}}}},
+		},
+		{
+			input:    "  ?! -  ",
+			expected: Query{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Parse(tt.input))
+		})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	assert.True(t, Parse("-shirt").Empty())
+	assert.False(t, Parse("shirt").Empty())
+}
@@ -0,0 +1,94 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// MinSimilarity is how similar a name has to be to a suggestion prefix,
+// when it does not start with it, to be suggested at all.
+const MinSimilarity = 0.3
+
+// Suggest picks up to limit distinct names for an autocomplete prefix:
+// names starting with it or containing it as a word come first, then names
+// similar enough to be a typo of it, best first.
+func Suggest(prefix string, names []string, limit int) []string {
+	type scored struct {
+		name       string
+		similarity float64
+	}
+	lowerPrefix := strings.ToLower(prefix)
+	seen := make(map[string]bool)
+	var candidates []scored
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		similarity := WordSimilarity(prefix, name)
+		if strings.HasPrefix(strings.ToLower(name), lowerPrefix) {
+			similarity = 1
+		}
+		if similarity >= MinSimilarity {
+			candidates = append(candidates, scored{name: name, similarity: similarity})
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b scored) int {
+		return cmp.Or(cmp.Compare(b.similarity, a.similarity), cmp.Compare(a.name, b.name))
+	})
+	suggestions := make([]string, 0, min(limit, len(candidates)))
+	for _, c := range candidates[:min(limit, len(candidates))] {
+		suggestions = append(suggestions, c.name)
+	}
+
+	return suggestions
+}
+
+// WordSimilarity scores from 0 to 1 how well query matches the best run of
+// text the way pg_trgm's word_similarity does: the trigrams shared with the
+// run over all the trigrams of both.
+func WordSimilarity(query, text string) float64 {
+	wanted := make(map[string]bool)
+	for _, t := range trigrams(query) {
+		wanted[t] = true
+	}
+	if len(wanted) == 0 {
+		return 0
+	}
+
+	sequence := trigrams(text)
+	best := 0.0
+	for start := range sequence {
+		if !wanted[sequence[start]] {
+			continue
+		}
+		shared := make(map[string]bool)
+		for end := start; end < len(sequence); end++ {
+			if !wanted[sequence[end]] {
+				continue
+			}
+			shared[sequence[end]] = true
+			length := end - start + 1
+			best = max(best, float64(len(shared))/float64(len(wanted)+length-len(shared)))
+		}
+	}
+
+	return best
+}
+
+// trigrams lists the trigrams of every word in order, each word padded
+// with two spaces in front and one behind like pg_trgm does.
+func trigrams(text string) []string {
+	var out []string
+	for _, word := range Words(text) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			out = append(out, string(padded[i:i+3]))
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,25 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, WordSimilarity("mug", "Coffee Mug"))
+	assert.InDelta(t, 0.43, WordSimilarity("iphnoe", "iPhone 15"), 0.01)
+	assert.InDelta(t, 0.67, WordSimilarity("phone", "iPhone 15"), 0.01)
+	assert.Less(t, WordSimilarity("case", "Coffee"), MinSimilarity)
+	assert.Equal(t, 0.0, WordSimilarity("", "Coffee"))
+}
+
+func TestSuggest(t *testing.T) {
+	names := []string{"iPhone Case", "iPhone 15", "Phone Stand", "Coffee Mug", "iPhone 15"}
+
+	assert.Equal(t, []string{"iPhone 15", "iPhone Case"}, Suggest("iph", names, 10))
+	assert.Equal(t, []string{"iPhone 15", "iPhone Case"}, Suggest("iphnoe", names, 10))
+	assert.Equal(t, []string{"Phone Stand", "iPhone 15", "iPhone Case"}, Suggest("phone", names, 10))
+	assert.Equal(t, []string{"iPhone 15"}, Suggest("iph", names, 1))
+	assert.Empty(t, Suggest("laptop", names, 10))
+}
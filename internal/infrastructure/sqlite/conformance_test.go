@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+	"github.com/rosset7i/product_crud/config"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/infrastructure/repotest"
+	"github.com/rosset7i/product_crud/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+// testDB opens a fresh, migrated database in a temporary directory.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := Open(context.Background(), &config.ConfDB{SQLitePath: filepath.Join(t.TempDir(), "test.db")}, slog.New(slog.DiscardHandler))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+
+	fsys, err := fs.Sub(migrations.SQLite, "sqlite")
+	assert.NoError(t, err)
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, fsys)
+	assert.NoError(t, err)
+	if _, err := provider.Up(context.Background()); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return db
+}
+
+func TestProductRepositoryConformance(t *testing.T) {
+	repotest.ProductRepository(t, func(t *testing.T) domain.ProductRepository {
+		return NewProductRepository(testDB(t), slog.New(slog.DiscardHandler))
+	})
+}
+
+func TestProductUpdatesWithinTx(t *testing.T) {
+	repotest.ProductUpdatesWithinTx(t, func(t *testing.T) (domain.ProductRepository, domain.TxManager) {
+		db := testDB(t)
+		logger := slog.New(slog.DiscardHandler)
+		return NewProductRepository(db, logger), NewTxManager(db, logger)
+	})
+}
+
+func TestUserRepositoryConformance(t *testing.T) {
+	repotest.UserRepository(t, func(t *testing.T) domain.UserRepository {
+		return NewUserRepository(testDB(t), slog.New(slog.DiscardHandler))
+	})
+}
+
+func TestViewRepositoryConformance(t *testing.T) {
+	repotest.ViewRepository(t, func(t *testing.T) (domain.UserRepository, domain.ViewRepository) {
+		db := testDB(t)
+		return NewUserRepository(db, slog.New(slog.DiscardHandler)), NewViewRepository(db, slog.New(slog.DiscardHandler))
+	})
+}
+
+func TestWebhookRepositoryConformance(t *testing.T) {
+	repotest.WebhookRepository(t, func(t *testing.T) (domain.UserRepository, domain.WebhookRepository, domain.WebhookDeliveryRepository) {
+		db := testDB(t)
+		logger := slog.New(slog.DiscardHandler)
+		return NewUserRepository(db, logger), NewWebhookRepository(db, logger), NewWebhookDeliveryRepository(db, logger)
+	})
+}
+
+func TestMigrationChecker(t *testing.T) {
+	db := testDB(t)
+	fsys, err := fs.Sub(migrations.SQLite, "sqlite")
+	assert.NoError(t, err)
+
+	checker, err := NewMigrationChecker(db, fsys)
+	assert.NoError(t, err)
+	assert.NoError(t, checker.Check(context.Background()))
+
+	_, err = db.Exec("INSERT INTO goose_db_version (version_id, is_applied) VALUES (99, true)")
+	assert.NoError(t, err)
+	assert.ErrorContains(t, checker.Check(context.Background()), "database is at migration 99")
+}
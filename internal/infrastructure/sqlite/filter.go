@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+)
+
+var (
+	filterOps = map[filter.Op]string{
+		filter.Eq: "=",
+		filter.Ne: "<>",
+		filter.Gt: ">",
+		filter.Ge: ">=",
+		filter.Lt: "<",
+		filter.Le: "<=",
+	}
+	likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+)
+
+// compileFilter turns a parsed filter into a SQL condition, appending its
+// values to args. Timestamps are stored as text with the writer's offset,
+// so they are compared through julianday rather than as strings. LIKE
+// already ignores case in SQLite, though for ASCII letters only.
+func compileFilter(e filter.Expr, args []any) (string, []any) {
+	switch e := e.(type) {
+	case filter.And:
+		left, args := compileFilter(e.Left, args)
+		right, args := compileFilter(e.Right, args)
+		return "(" + left + " AND " + right + ")", args
+	case filter.Or:
+		left, args := compileFilter(e.Left, args)
+		right, args := compileFilter(e.Right, args)
+		return "(" + left + " OR " + right + ")", args
+	case filter.Not:
+		inner, args := compileFilter(e.Expr, args)
+		return "NOT " + inner, args
+	case filter.Comparison:
+		switch v := e.Value.(type) {
+		case time.Time:
+			return fmt.Sprintf("julianday(%s) %s julianday(?)", e.Field, filterOps[e.Op]), append(args, v.UTC())
+		case string:
+			switch e.Op {
+			case filter.Co:
+				return fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, e.Field), append(args, "%"+likeEscaper.Replace(v)+"%")
+			case filter.Sw:
+				return fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, e.Field), append(args, likeEscaper.Replace(v)+"%")
+			}
+		}
+		return fmt.Sprintf("%s %s ?", e.Field, filterOps[e.Op]), append(args, e.Value)
+	}
+
+	panic(fmt.Sprintf("unexpected filter expression %T", e))
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+)
+
+// MigrationChecker compares the version recorded by goose with the newest
+// migration shipped in the binary.
+type MigrationChecker struct {
+	db       *sql.DB
+	expected int64
+}
+
+// NewMigrationChecker reads the expected version from the migrations at
+// the root of the given FS.
+func NewMigrationChecker(db *sql.DB, migrations fs.FS) (*MigrationChecker, error) {
+	files, err := fs.Glob(migrations, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var expected int64
+	for _, file := range files {
+		version, err := goose.NumericComponent(file)
+		if err != nil {
+			return nil, err
+		}
+		expected = max(expected, version)
+	}
+
+	return &MigrationChecker{
+		db:       db,
+		expected: expected,
+	}, nil
+}
+
+func (c *MigrationChecker) Check(ctx context.Context) error {
+	current, err := c.version(ctx)
+	if err != nil {
+		return err
+	}
+	if current != c.expected {
+		return fmt.Errorf("database is at migration %d, expected %d", current, c.expected)
+	}
+
+	return nil
+}
+
+// version reads the goose table the same way goose does, skipping versions
+// that were rolled back, without creating the table when it is missing.
+func (c *MigrationChecker) version(ctx context.Context) (int64, error) {
+	rows, err := c.db.QueryContext(ctx, "SELECT version_id, is_applied FROM goose_db_version ORDER BY id DESC")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	rolledBack := make(map[int64]bool)
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+		)
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, err
+		}
+		if rolledBack[version] {
+			continue
+		}
+		if applied {
+			return version, nil
+		}
+		rolledBack[version] = true
+	}
+
+	return 0, rows.Err()
+}
@@ -0,0 +1,313 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+	"github.com/rosset7i/product_crud/internal/infrastructure/search"
+)
+
+type ProductRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewProductRepository(db *sql.DB, logger *slog.Logger) *ProductRepository {
+	return &ProductRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ProductRepository) FetchPaged(ctx context.Context, pageNumber, pageSize int, sort string, where filter.Expr) ([]*domain.Product, error) {
+	if sort != "asc" && sort != "desc" {
+		sort = "asc"
+	}
+
+	// unlike Postgres, SQLite reads a negative LIMIT or OFFSET as none
+	offset := (pageNumber - 1) * pageSize
+	if offset < 0 || pageSize < 0 {
+		return nil, errNegativePage
+	}
+
+	statement := "SELECT id, name, price, created_at, updated_at FROM products"
+	var args []any
+	if where != nil {
+		var condition string
+		condition, args = compileFilter(where, args)
+		statement += " WHERE " + condition
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		statement+" ORDER BY name "+sort+" LIMIT ? OFFSET ?",
+		append(args, pageSize, offset)...,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query products", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]*domain.Product, 0)
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(&p.Id, &p.Name, &p.Price, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		products = append(products, &p)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *ProductRepository) FetchById(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	var p domain.Product
+	err := conn(ctx, r.db).QueryRowContext(
+		ctx,
+		`SELECT id, name, price, created_at, updated_at
+		FROM products
+		WHERE id = ?`,
+		id,
+	).Scan(&p.Id, &p.Name, &p.Price, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrProductNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query product", "product_id", id, "err", err)
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// FetchByIdForUpdate needs no locking of its own: transactions begin
+// IMMEDIATE, which already keeps every other writer waiting.
+func (r *ProductRepository) FetchByIdForUpdate(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	return r.FetchById(ctx, id)
+}
+
+// FetchByIds passes the ids as a JSON array, keeping the statement the same
+// whatever their count.
+func (r *ProductRepository) FetchByIds(ctx context.Context, ids []uuid.UUID) ([]*domain.Product, error) {
+	encoded, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.fetch(ctx, "WHERE id IN (SELECT value FROM json_each(?))", string(encoded))
+}
+
+// Search ranks with bm25 over the products_fts index, which stems English
+// regardless of DB_SEARCH_LANGUAGE.
+func (r *ProductRepository) Search(ctx context.Context, query string, pageNumber, pageSize int) ([]*domain.ProductMatch, error) {
+	offset := (pageNumber - 1) * pageSize
+	if offset < 0 || pageSize < 0 {
+		return nil, errNegativePage
+	}
+	q := search.Parse(query)
+	if q.Empty() {
+		return make([]*domain.ProductMatch, 0), nil
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT p.id, p.name, p.price, p.created_at, p.updated_at, highlight(products_fts, 0, '<mark>', '</mark>')
+		FROM products_fts
+		JOIN products p ON p.search_key = products_fts.rowid
+		WHERE products_fts MATCH ?
+		ORDER BY bm25(products_fts), p.name
+		LIMIT ? OFFSET ?`,
+		ftsQuery(q), pageSize, offset,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not search products", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*domain.ProductMatch, 0)
+	for rows.Next() {
+		var m domain.ProductMatch
+		var p domain.Product
+		if err := rows.Scan(&p.Id, &p.Name, &p.Price, &p.CreatedAt, &p.UpdatedAt, &m.Highlight); err != nil {
+			return nil, err
+		}
+		m.Product = &p
+		matches = append(matches, &m)
+	}
+
+	return matches, rows.Err()
+}
+
+// CountByPrice finds each product's bucket by counting the bounds at or
+// below its price.
+func (r *ProductRepository) CountByPrice(ctx context.Context, query string, where filter.Expr, bounds domain.PriceBounds) ([]int, error) {
+	counts := make([]int, len(bounds)+1)
+	encodedBounds, err := json.Marshal(bounds)
+	if err != nil {
+		return nil, err
+	}
+	statement := `SELECT (SELECT count(*) FROM json_each(?) WHERE value <= p.price) AS bucket, count(*)
+		FROM products p`
+	args := []any{string(encodedBounds)}
+	conditions := []string{"1"}
+	if query != "" {
+		q := search.Parse(query)
+		if q.Empty() {
+			return counts, nil
+		}
+		conditions = append(conditions, "p.search_key IN (SELECT rowid FROM products_fts WHERE products_fts MATCH ?)")
+		args = append(args, ftsQuery(q))
+	}
+	if where != nil {
+		var condition string
+		condition, args = compileFilter(where, args)
+		conditions = append(conditions, condition)
+	}
+	statement += " WHERE " + strings.Join(conditions, " AND ")
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, statement+" GROUP BY bucket", args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not count products by price", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		counts[bucket] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// Suggest scores every distinct name in Go, SQLite having no trigram
+// similarity, which is fine for the catalogs SQLite is meant for.
+func (r *ProductRepository) Suggest(ctx context.Context, prefix string, limit int) ([]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT DISTINCT name FROM products")
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query product names", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return search.Suggest(prefix, names, limit), nil
+}
+
+// FetchSimilar narrows candidates down by price in SQL and compares names
+// in Go.
+func (r *ProductRepository) FetchSimilar(ctx context.Context, product *domain.Product, criteria domain.DuplicateCriteria, limit int) ([]*domain.Product, error) {
+	low, high := criteria.PriceRange(product.Price)
+	candidates, err := r.fetch(ctx, "WHERE price BETWEEN ? AND ? AND id <> ?", low, high, product.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return search.Similar(product, candidates, criteria, limit), nil
+}
+
+// FetchDuplicatePairs compares the whole catalog in Go.
+func (r *ProductRepository) FetchDuplicatePairs(ctx context.Context, criteria domain.DuplicateCriteria, limit int) ([][2]*domain.Product, error) {
+	products, err := r.fetch(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return search.DuplicatePairs(products, criteria, limit), nil
+}
+
+func (r *ProductRepository) fetch(ctx context.Context, where string, args ...any) ([]*domain.Product, error) {
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT id, name, price, created_at, updated_at
+		FROM products `+where,
+		args...,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query products", "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]*domain.Product, 0)
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(&p.Id, &p.Name, &p.Price, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		products = append(products, &p)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"INSERT INTO products (id, name, price, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		product.Id,
+		product.Name,
+		product.Price,
+		product.CreatedAt,
+		product.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not insert product", "product_id", product.Id, "err", err)
+	}
+
+	return err
+}
+
+func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"UPDATE products SET name = ?, price = ?, updated_at = ? WHERE id = ?",
+		product.Name,
+		product.Price,
+		product.UpdatedAt,
+		product.Id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not update product", "product_id", product.Id, "err", err)
+		return err
+	}
+
+	return affected(result, domain.ErrProductNotFound)
+}
+
+func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"DELETE FROM products WHERE id = ?",
+		id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not delete product", "product_id", id, "err", err)
+		return err
+	}
+
+	return affected(result, domain.ErrProductNotFound)
+}
@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/rosset7i/product_crud/internal/infrastructure/search"
+)
+
+// ftsQuery turns a web search style query into an FTS5 match expression.
+// Every word is quoted, so user input can never be read as FTS5 syntax.
+func ftsQuery(q search.Query) string {
+	clauses := make([]string, len(q.Clauses))
+	for i, clause := range q.Clauses {
+		phrases := make([]string, len(clause))
+		for j, phrase := range clause {
+			phrases[j] = ftsPhrase(phrase)
+		}
+		clauses[i] = "(" + strings.Join(phrases, " OR ") + ")"
+	}
+
+	expression := strings.Join(clauses, " AND ")
+	for _, phrase := range q.Excluded {
+		expression += " NOT " + ftsPhrase(phrase)
+	}
+
+	return expression
+}
+
+func ftsPhrase(phrase search.Phrase) string {
+	words := make([]string, len(phrase))
+	for i, word := range phrase {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+
+	return strings.Join(words, " + ")
+}
@@ -0,0 +1,178 @@
+// Package sqlite implements the domain repositories on SQLite through a
+// pure-Go driver, for single-node deployments that cannot run Postgres. The
+// schema lives in migrations/sqlite and mirrors the Postgres one.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/rosset7i/product_crud/config"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const busyTimeoutMs = 5000
+
+var errNegativePage = errors.New("page number and size must not be negative")
+
+// DSN turns a file path into a connection string that enforces foreign
+// keys, waits on a locked database instead of failing, and takes the write
+// lock when a transaction begins so it cannot fail halfway through.
+func DSN(path string) string {
+	return fmt.Sprintf(
+		"file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_txlock=immediate&_time_format=sqlite",
+		path, busyTimeoutMs,
+	)
+}
+
+// Open opens the database file in c.SQLitePath. The schema is expected to
+// be migrated already, as with Postgres.
+func Open(ctx context.Context, c *config.ConfDB, logger *slog.Logger) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", DSN(c.SQLitePath))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", c.SQLitePath, err)
+	}
+
+	logger.Info("opened sqlite database", "path", c.SQLitePath)
+	return db, nil
+}
+
+// querier is what repositories run statements on: the database, or the
+// transaction carried by the context.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// commitHooksKey carries the functions AfterCommit defers until the
+// transaction or savepoint they were registered in commits.
+type commitHooksKey struct{}
+
+type commitHooks []func()
+
+// conn returns the transaction started by TxManager.WithinTx, if any, and
+// otherwise the given database.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// TxManager implements domain.TxManager on a SQLite database.
+type TxManager struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewTxManager(db *sql.DB, logger *slog.Logger) *TxManager {
+	return &TxManager{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithinTx runs fn in a transaction that every repository call made with the
+// context it receives joins. Inside another WithinTx it runs in a savepoint
+// instead, so only its own work is rolled back on error. SQLite has a single
+// writer, so there are no serialization failures to retry; contention waits
+// on the busy timeout instead.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		hooks := &commitHooks{}
+		if err := savepoint(context.WithValue(ctx, commitHooksKey{}, hooks), tx, fn); err != nil {
+			return err
+		}
+		// the savepoint's work now stands or falls with the enclosing one
+		parent := ctx.Value(commitHooksKey{}).(*commitHooks)
+		*parent = append(*parent, *hooks...)
+		return nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	hooks := &commitHooks{}
+	if err := fn(withTx(ctx, tx, hooks)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			m.logger.WarnContext(ctx, "could not roll back transaction", "err", rollbackErr)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, hook := range *hooks {
+		hook()
+	}
+	return nil
+}
+
+func (m *TxManager) InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return ok
+}
+
+func (m *TxManager) AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		*hooks = append(*hooks, fn)
+		return
+	}
+
+	fn()
+}
+
+func withTx(ctx context.Context, tx *sql.Tx, hooks *commitHooks) context.Context {
+	return context.WithValue(context.WithValue(ctx, txKey{}, tx), commitHooksKey{}, hooks)
+}
+
+func savepoint(ctx context.Context, tx *sql.Tx, fn func(ctx context.Context) error) error {
+	// savepoints nest by name, the innermost one with the name is released
+	// or rolled back, so one name serves every level
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT nested"); err != nil {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO nested"); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		_, releaseErr := tx.ExecContext(ctx, "RELEASE nested")
+		return errors.Join(err, releaseErr)
+	}
+	_, err := tx.ExecContext(ctx, "RELEASE nested")
+
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// affected returns errNotFound when the statement matched no row.
+func affected(result sql.Result, errNotFound error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithinTxRollsBackOnError(t *testing.T) {
+	db := testDB(t)
+	logger := slog.New(slog.DiscardHandler)
+	products := NewProductRepository(db, logger)
+	tx := NewTxManager(db, logger)
+	p, _ := domain.NewProduct("shirt", 10)
+	errFailed := errors.New("failed")
+
+	err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		assert.NoError(t, products.Create(ctx, p))
+		return errFailed
+	})
+
+	assert.ErrorIs(t, err, errFailed)
+	_, err = products.FetchById(context.Background(), p.Id)
+	assert.ErrorIs(t, err, domain.ErrProductNotFound)
+}
+
+func TestNestedWithinTxRollsBackItsOwnWork(t *testing.T) {
+	db := testDB(t)
+	logger := slog.New(slog.DiscardHandler)
+	products := NewProductRepository(db, logger)
+	tx := NewTxManager(db, logger)
+	outer, _ := domain.NewProduct("shirt", 10)
+	inner, _ := domain.NewProduct("hat", 5)
+	errFailed := errors.New("failed")
+
+	err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		assert.NoError(t, products.Create(ctx, outer))
+		err := tx.WithinTx(ctx, func(ctx context.Context) error {
+			assert.NoError(t, products.Create(ctx, inner))
+			return errFailed
+		})
+		assert.ErrorIs(t, err, errFailed)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	_, err = products.FetchById(context.Background(), outer.Id)
+	assert.NoError(t, err)
+	_, err = products.FetchById(context.Background(), inner.Id)
+	assert.ErrorIs(t, err, domain.ErrProductNotFound)
+}
+
+func TestAfterCommitRunsOnlyForCommittedWork(t *testing.T) {
+	db := testDB(t)
+	tx := NewTxManager(db, slog.New(slog.DiscardHandler))
+	errFailed := errors.New("failed")
+	var ran []string
+
+	tx.AfterCommit(context.Background(), func() { ran = append(ran, "outside") })
+	err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		tx.AfterCommit(ctx, func() { ran = append(ran, "outer") })
+		_ = tx.WithinTx(ctx, func(ctx context.Context) error {
+			tx.AfterCommit(ctx, func() { ran = append(ran, "released") })
+			return nil
+		})
+		_ = tx.WithinTx(ctx, func(ctx context.Context) error {
+			tx.AfterCommit(ctx, func() { ran = append(ran, "rolled back") })
+			return errFailed
+		})
+		assert.Equal(t, []string{"outside"}, ran)
+		return nil
+	})
+	assert.NoError(t, err)
+	err = tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		tx.AfterCommit(ctx, func() { ran = append(ran, "failed") })
+		return errFailed
+	})
+
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, []string{"outside", "outer", "released"}, ran)
+}
+
+func TestWebhookDeliveriesAreDeletedWithTheirWebhook(t *testing.T) {
+	db := testDB(t)
+	logger := slog.New(slog.DiscardHandler)
+	webhooks := NewWebhookRepository(db, logger)
+	deliveries := NewWebhookDeliveryRepository(db, logger)
+	owner, err := domain.NewUser("Ada", "ada@example.com", "secret")
+	assert.NoError(t, err)
+	assert.NoError(t, NewUserRepository(db, logger).Create(context.Background(), owner))
+	w, err := domain.NewWebhook(owner.Id, "https://example.com/hook", []domain.EventType{domain.EventProductCreated}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, webhooks.Create(context.Background(), w))
+	d := domain.NewWebhookDelivery(w.Id, w.Id, domain.EventProductCreated, []byte(`{}`))
+	assert.NoError(t, deliveries.Create(context.Background(), d))
+
+	subscribed, err := webhooks.FetchByEvent(context.Background(), domain.EventProductCreated)
+	assert.NoError(t, err)
+	assert.Len(t, subscribed, 1)
+	assert.Equal(t, w.Events, subscribed[0].Events)
+
+	assert.NoError(t, webhooks.Delete(context.Background(), w.Id))
+	_, err = deliveries.FetchById(context.Background(), d.Id)
+	assert.ErrorIs(t, err, domain.ErrWebhookDeliveryNotFound)
+}
+
+func TestSearchSurvivesVacuum(t *testing.T) {
+	db := testDB(t)
+	products := NewProductRepository(db, slog.New(slog.DiscardHandler))
+	for _, name := range []string{"red shirt", "blue hat", "green shirt"} {
+		p, _ := domain.NewProduct(name, 10)
+		assert.NoError(t, products.Create(context.Background(), p))
+		if name == "red shirt" {
+			assert.NoError(t, products.Delete(context.Background(), p.Id))
+		}
+	}
+
+	_, err := db.ExecContext(context.Background(), "VACUUM")
+	assert.NoError(t, err)
+
+	matches, err := products.Search(context.Background(), "shirt", 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, "green shirt", matches[0].Product.Name)
+	}
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type UserRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewUserRepository(db *sql.DB, logger *slog.Logger) *UserRepository {
+	return &UserRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *UserRepository) FetchByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var u domain.User
+	err := conn(ctx, r.db).QueryRowContext(
+		ctx,
+		`SELECT id, name, email, password_hash, created_at, updated_at
+		FROM users
+		WHERE email = ?`,
+		email,
+	).Scan(&u.Id, &u.Name, &u.Email, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query user", "err", err)
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"INSERT INTO users (id, name, email, password_hash, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		user.Id,
+		user.Name,
+		user.Email,
+		user.PasswordHash,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrUserAlreadyExists
+		}
+		r.logger.ErrorContext(ctx, "could not insert user", "user_id", user.Id, "err", err)
+	}
+
+	return err
+}
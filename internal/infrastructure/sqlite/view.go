@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// ViewRepository stores the columns and the users a view is shared with as
+// JSON arrays, SQLite having no array type.
+type ViewRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewViewRepository(db *sql.DB, logger *slog.Logger) *ViewRepository {
+	return &ViewRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ViewRepository) FetchVisible(ctx context.Context, userId uuid.UUID) ([]*domain.View, error) {
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT id, owner_id, name, filter, sort, columns, shared_with, created_at, updated_at
+		FROM views
+		WHERE owner_id = ?1 OR EXISTS (SELECT 1 FROM json_each(shared_with) WHERE value = ?1)
+		ORDER BY name, created_at`,
+		userId,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query views", "user_id", userId, "err", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := make([]*domain.View, 0)
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, v)
+	}
+
+	return views, rows.Err()
+}
+
+func (r *ViewRepository) FetchById(ctx context.Context, id uuid.UUID) (*domain.View, error) {
+	v, err := scanView(conn(ctx, r.db).QueryRowContext(
+		ctx,
+		`SELECT id, owner_id, name, filter, sort, columns, shared_with, created_at, updated_at
+		FROM views
+		WHERE id = ?`,
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrViewNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query view", "view_id", id, "err", err)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (r *ViewRepository) Create(ctx context.Context, view *domain.View) error {
+	columns, err := json.Marshal(view.Columns)
+	if err != nil {
+		return err
+	}
+	sharedWith, err := json.Marshal(view.SharedWith)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(
+		ctx,
+		`INSERT INTO views (id, owner_id, name, filter, sort, columns, shared_with, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		view.Id,
+		view.OwnerId,
+		view.Name,
+		view.Filter,
+		view.Sort,
+		string(columns),
+		string(sharedWith),
+		view.CreatedAt,
+		view.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not insert view", "view_id", view.Id, "err", err)
+	}
+
+	return err
+}
+
+func (r *ViewRepository) Update(ctx context.Context, view *domain.View) error {
+	columns, err := json.Marshal(view.Columns)
+	if err != nil {
+		return err
+	}
+	sharedWith, err := json.Marshal(view.SharedWith)
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"UPDATE views SET name = ?, filter = ?, sort = ?, columns = ?, shared_with = ?, updated_at = ? WHERE id = ?",
+		view.Name,
+		view.Filter,
+		view.Sort,
+		string(columns),
+		string(sharedWith),
+		view.UpdatedAt,
+		view.Id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not update view", "view_id", view.Id, "err", err)
+		return err
+	}
+
+	return affected(result, domain.ErrViewNotFound)
+}
+
+func (r *ViewRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"DELETE FROM views WHERE id = ?",
+		id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not delete view", "view_id", id, "err", err)
+		return err
+	}
+
+	return affected(result, domain.ErrViewNotFound)
+}
+
+func scanView(row interface{ Scan(dest ...any) error }) (*domain.View, error) {
+	var v domain.View
+	var columns, sharedWith []byte
+	if err := row.Scan(&v.Id, &v.OwnerId, &v.Name, &v.Filter, &v.Sort, &columns, &sharedWith, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(columns, &v.Columns); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(sharedWith, &v.SharedWith); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// WebhookRepository stores the subscribed events as a JSON array, SQLite
+// having no array type.
+type WebhookRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewWebhookRepository(db *sql.DB, logger *slog.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *WebhookRepository) FetchByOwner(ctx context.Context, ownerId uuid.UUID) ([]*domain.Webhook, error) {
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT id, owner_id, url, events, secret, created_at, updated_at
+		FROM webhooks
+		WHERE owner_id = ?
+		ORDER BY created_at`,
+		ownerId,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query webhooks", "owner_id", ownerId, "err", err)
+		return nil, err
+	}
+
+	return scanWebhooks(rows)
+}
+
+func (r *WebhookRepository) FetchById(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	var w domain.Webhook
+	var events []byte
+	err := conn(ctx, r.db).QueryRowContext(
+		ctx,
+		`SELECT id, owner_id, url, events, secret, created_at, updated_at
+		FROM webhooks
+		WHERE id = ?`,
+		id,
+	).Scan(&w.Id, &w.OwnerId, &w.Url, &events, &w.Secret, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query webhook", "webhook_id", id, "err", err)
+		return nil, err
+	}
+	if err := json.Unmarshal(events, &w.Events); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (r *WebhookRepository) FetchByEvent(ctx context.Context, eventType domain.EventType) ([]*domain.Webhook, error) {
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT id, owner_id, url, events, secret, created_at, updated_at
+		FROM webhooks
+		WHERE EXISTS (SELECT 1 FROM json_each(webhooks.events) WHERE value = ?)`,
+		string(eventType),
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query webhooks by event", "event_type", eventType, "err", err)
+		return nil, err
+	}
+
+	return scanWebhooks(rows)
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(
+		ctx,
+		"INSERT INTO webhooks (id, owner_id, url, events, secret, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		webhook.Id,
+		webhook.OwnerId,
+		webhook.Url,
+		string(events),
+		webhook.Secret,
+		webhook.CreatedAt,
+		webhook.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not insert webhook", "webhook_id", webhook.Id, "err", err)
+	}
+
+	return err
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"UPDATE webhooks SET url = ?, events = ?, secret = ?, updated_at = ? WHERE id = ?",
+		webhook.Url,
+		string(events),
+		webhook.Secret,
+		webhook.UpdatedAt,
+		webhook.Id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not update webhook", "webhook_id", webhook.Id, "err", err)
+		return err
+	}
+
+	return affected(result, domain.ErrWebhookNotFound)
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"DELETE FROM webhooks WHERE id = ?",
+		id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not delete webhook", "webhook_id", id, "err", err)
+		return err
+	}
+
+	return affected(result, domain.ErrWebhookNotFound)
+}
+
+func scanWebhooks(rows *sql.Rows) ([]*domain.Webhook, error) {
+	defer rows.Close()
+
+	webhooks := make([]*domain.Webhook, 0)
+	for rows.Next() {
+		var w domain.Webhook
+		var events []byte
+		if err := rows.Scan(&w.Id, &w.OwnerId, &w.Url, &events, &w.Secret, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(events, &w.Events); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, &w)
+	}
+
+	return webhooks, rows.Err()
+}
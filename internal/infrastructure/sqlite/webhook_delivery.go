@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type WebhookDeliveryRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewWebhookDeliveryRepository(db *sql.DB, logger *slog.Logger) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *WebhookDeliveryRepository) FetchPagedByWebhook(ctx context.Context, webhookId uuid.UUID, pageNumber, pageSize int) ([]*domain.WebhookDelivery, error) {
+	offset := (pageNumber - 1) * pageSize
+	if offset < 0 || pageSize < 0 {
+		return nil, errNegativePage
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT id, webhook_id, event_id, event_type, payload, status, attempts, response_code, error, delivered_at, next_attempt_at, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?`,
+		webhookId, pageSize, offset,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query webhook deliveries", "webhook_id", webhookId, "err", err)
+		return nil, err
+	}
+
+	return scanDeliveries(rows)
+}
+
+func (r *WebhookDeliveryRepository) FetchById(ctx context.Context, id uuid.UUID) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	err := conn(ctx, r.db).QueryRowContext(
+		ctx,
+		`SELECT id, webhook_id, event_id, event_type, payload, status, attempts, response_code, error, delivered_at, next_attempt_at, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE id = ?`,
+		id,
+	).Scan(
+		&d.Id, &d.WebhookId, &d.EventId, &d.EventType, &d.Payload, &d.Status,
+		&d.Attempts, &d.ResponseCode, &d.Error, &d.DeliveredAt, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		r.logger.ErrorContext(ctx, "could not query webhook delivery", "delivery_id", id, "err", err)
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+func (r *WebhookDeliveryRepository) FetchPending(ctx context.Context) ([]*domain.WebhookDelivery, error) {
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT id, webhook_id, event_id, event_type, payload, status, attempts, response_code, error, delivered_at, next_attempt_at, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE status = ?
+		ORDER BY created_at`,
+		string(domain.DeliveryPending),
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not query pending webhook deliveries", "err", err)
+		return nil, err
+	}
+
+	return scanDeliveries(rows)
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		`INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, attempts, response_code, error, delivered_at, next_attempt_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		delivery.Id,
+		delivery.WebhookId,
+		delivery.EventId,
+		string(delivery.EventType),
+		delivery.Payload,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.Error,
+		delivery.DeliveredAt,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not insert webhook delivery", "delivery_id", delivery.Id, "err", err)
+	}
+
+	return err
+}
+
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		`UPDATE webhook_deliveries SET status = ?, attempts = ?, response_code = ?, error = ?, delivered_at = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ?`,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.Error,
+		delivery.DeliveredAt,
+		delivery.NextAttemptAt,
+		delivery.UpdatedAt,
+		delivery.Id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not update webhook delivery", "delivery_id", delivery.Id, "err", err)
+		return err
+	}
+
+	return affected(result, domain.ErrWebhookDeliveryNotFound)
+}
+
+func (r *WebhookDeliveryRepository) Claim(ctx context.Context, delivery *domain.WebhookDelivery, until time.Time) error {
+	now := time.Now()
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		`UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND attempts = ?`,
+		until,
+		now,
+		delivery.Id,
+		string(domain.DeliveryPending),
+		delivery.Attempts,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "could not claim webhook delivery", "delivery_id", delivery.Id, "err", err)
+		return err
+	}
+	if err := affected(result, domain.ErrWebhookDeliveryClaimed); err != nil {
+		return err
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = until
+	delivery.UpdatedAt = now
+
+	return nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		if err := rows.Scan(
+			&d.Id, &d.WebhookId, &d.EventId, &d.EventType, &d.Payload, &d.Status,
+			&d.Attempts, &d.ResponseCode, &d.Error, &d.DeliveredAt, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteConditionalJSON(t *testing.T) {
+	lastModified := time.Date(2025, 1, 2, 3, 4, 5, 600, time.UTC)
+	etag := ETag("id", "1")
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		status int
+	}{
+		{"no preconditions", nil, http.StatusOK},
+		{"matching etag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"weak matching etag in list", map[string]string{"If-None-Match": `"other", W/` + etag}, http.StatusNotModified},
+		{"wildcard etag", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"stale etag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"stale etag wins over fresh date", map[string]string{
+			"If-None-Match":     `"other"`,
+			"If-Modified-Since": lastModified.Format(http.TimeFormat),
+		}, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+
+			WriteConditionalJSON(w, r, http.StatusOK, map[string]string{"name": "Product"}, etag, lastModified)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Equal(t, "Thu, 02 Jan 2025 03:04:05 GMT", w.Header().Get("Last-Modified"))
+			if tt.status == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			} else {
+				assert.JSONEq(t, `{"name":"Product"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWriteConditionalJSONDerivesETagFromContent(t *testing.T) {
+	write := func(payload any) string {
+		w := httptest.NewRecorder()
+		WriteConditionalJSON(w, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, payload, "", time.Time{})
+		assert.Empty(t, w.Header().Get("Last-Modified"))
+		return w.Header().Get("ETag")
+	}
+
+	assert.Equal(t, write([]int{1, 2}), write([]int{1, 2}))
+	assert.NotEqual(t, write([]int{1, 2}), write([]int{2, 1}))
+}
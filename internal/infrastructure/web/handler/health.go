@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/rosset7i/product_crud/internal/infrastructure/health"
+	"github.com/rosset7i/product_crud/internal/infrastructure/web"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// Liveness godoc
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  health.Report
+// @Router       /healthz [get]
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	web.WriteJSON(w, http.StatusOK, h.checker.Live())
+}
+
+// Readiness godoc
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  health.Report
+// @Failure      503  {object}  health.Report
+// @Router       /readyz [get]
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Ready(r.Context())
+	if report.Status != health.StatusOk {
+		web.WriteJSON(w, http.StatusServiceUnavailable, report)
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, report)
+}
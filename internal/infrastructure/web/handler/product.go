@@ -1,85 +1,168 @@
 package handler
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
 	"github.com/rosset7i/product_crud/internal/infrastructure/web"
+	"github.com/rosset7i/product_crud/internal/usecase"
 	"github.com/rosset7i/product_crud/internal/usecase/product"
 )
 
 type ProductHandler struct {
-	fetchPagedProductsUseCase *product.FetchPagedProductsUseCase
-	fetchByIdUseCase          *product.FetchByIdUseCase
-	createUseCase             *product.CreateUseCase
-	updateUseCase             *product.UpdateUseCase
-	deleteUseCase             *product.DeleteUseCase
+	fetchPagedProductsUseCase usecase.UseCase[product.FetchPagedProductsRequest, product.FetchPagedProductsResponse]
+	fetchByIdUseCase          usecase.UseCase[product.FetchByIdRequest, product.FetchByIdResponse]
+	fetchByIdsUseCase         usecase.UseCase[product.FetchByIdsRequest, product.FetchByIdsResponse]
+	searchUseCase             usecase.UseCase[product.SearchRequest, product.SearchResponse]
+	suggestUseCase            usecase.UseCase[product.SuggestRequest, product.SuggestResponse]
+	fetchDuplicatesUseCase    usecase.UseCase[product.FetchDuplicatesRequest, product.FetchDuplicatesResponse]
+	createUseCase             usecase.UseCase[product.CreateRequest, product.CreateResponse]
+	updateUseCase             usecase.UseCase[product.UpdateRequest, product.UpdateResponse]
+	deleteUseCase             usecase.UseCase[product.DeleteRequest, product.DeleteResponse]
+	logger                    *slog.Logger
 }
 
 func NewProductHandler(
-	fetchPagedProductsUseCase *product.FetchPagedProductsUseCase,
-	fetchByIdUseCase *product.FetchByIdUseCase,
-	createUseCase *product.CreateUseCase,
-	updateUseCase *product.UpdateUseCase,
-	deleteUseCase *product.DeleteUseCase,
+	fetchPagedProductsUseCase usecase.UseCase[product.FetchPagedProductsRequest, product.FetchPagedProductsResponse],
+	fetchByIdUseCase usecase.UseCase[product.FetchByIdRequest, product.FetchByIdResponse],
+	fetchByIdsUseCase usecase.UseCase[product.FetchByIdsRequest, product.FetchByIdsResponse],
+	searchUseCase usecase.UseCase[product.SearchRequest, product.SearchResponse],
+	suggestUseCase usecase.UseCase[product.SuggestRequest, product.SuggestResponse],
+	fetchDuplicatesUseCase usecase.UseCase[product.FetchDuplicatesRequest, product.FetchDuplicatesResponse],
+	createUseCase usecase.UseCase[product.CreateRequest, product.CreateResponse],
+	updateUseCase usecase.UseCase[product.UpdateRequest, product.UpdateResponse],
+	deleteUseCase usecase.UseCase[product.DeleteRequest, product.DeleteResponse],
+	logger *slog.Logger,
 ) *ProductHandler {
 	return &ProductHandler{
 		fetchPagedProductsUseCase: fetchPagedProductsUseCase,
 		fetchByIdUseCase:          fetchByIdUseCase,
+		fetchByIdsUseCase:         fetchByIdsUseCase,
+		searchUseCase:             searchUseCase,
+		suggestUseCase:            suggestUseCase,
+		fetchDuplicatesUseCase:    fetchDuplicatesUseCase,
 		createUseCase:             createUseCase,
 		updateUseCase:             updateUseCase,
 		deleteUseCase:             deleteUseCase,
+		logger:                    logger,
 	}
 }
 
 // List Products godoc
+// @Description  With ids, returns those products instead, as product.FetchByIdsResponse, and only fields and expand apply.
 // @Tags         products
 // @Accept       json
 // @Produce      json
-// @Param        pageNumber  query     int     true "pageNumber"
-// @Param        pageSize    query     int     true "pageSize"
-// @Param        sort        query     string  true "sort"
-// @Success      200         {object}  product.FetchPagedProductsResponse
-// @Failure      400         {object}  web.errorResponse
-// @Failure      422         {object}  web.errorResponse
+// @Param        ids            query     string  false "comma separated ids of the products to fetch, up to 100 including repeats"
+// @Param        pageNumber     query     int     false "pageNumber, required unless ids are given"
+// @Param        pageSize       query     int     false "pageSize, required unless ids are given"
+// @Param        sort           query     string  false "asc or desc, required unless a view is given"
+// @Param        view           query     string  false "id of a saved view to run"
+// @Param        filter         query     string  false "expression such as price ge 10 and not created_at gt 2025-01-01; fields id name price created_at updated_at; operators eq ne gt ge lt le co sw; combine with and or not and parentheses; name values are double quoted"
+// @Param        facets         query     string  false "comma separated facets to count, only price is supported"
+// @Param        fields         query     string  false "comma separated fields to return: id, name, price, created_at, updated_at"
+// @Param        expand         query     string  false "comma separated related resources to embed, none exist yet"
+// @Param        If-None-Match  header    string  false "ETag of the cached page"
+// @Success      200            {object}  product.FetchPagedProductsResponse
+// @Success      304            "Not Modified"
+// @Failure      400            {object}  web.errorResponse
+// @Failure      422            {object}  web.errorResponse
 // @Router       /v1/products [get]
 // @Security Bearer
 func (h *ProductHandler) FetchPaged(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("ids") {
+		ids := make([]uuid.UUID, 0)
+		for _, value := range queryList(r, "ids") {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				web.WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			ids = append(ids, id)
+		}
+		h.fetchByIds(w, r, product.FetchByIdsRequest{
+			Ids:    ids,
+			Fields: queryList(r, "fields"),
+			Expand: queryList(r, "expand"),
+		})
+		return
+	}
+
 	pageNumber, err := strconv.Atoi(r.URL.Query().Get("pageNumber"))
 	if err != nil {
 		web.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	sort := r.URL.Query().Get("sort")
-	if err != nil || sort == "" {
+	if err != nil {
 		web.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	var viewId uuid.UUID
+	if raw := r.URL.Query().Get("view"); raw != "" {
+		if viewId, err = uuid.Parse(raw); err != nil {
+			web.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	sort := r.URL.Query().Get("sort")
+	if sort == "" && viewId == uuid.Nil {
+		web.WriteError(w, http.StatusBadRequest, "sort is required")
+		return
+	}
+	userId, err := web.UserId(r)
+	if err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
 
 	response, err := h.fetchPagedProductsUseCase.Execute(r.Context(), product.FetchPagedProductsRequest{
 		PageNumber: pageNumber,
 		PageSize:   pageSize,
 		Sort:       sort,
+		Filter:     r.URL.Query().Get("filter"),
+		Facets:     queryList(r, "facets"),
+		Fields:     queryList(r, "fields"),
+		Expand:     queryList(r, "expand"),
+		ViewId:     viewId,
+		UserId:     userId,
 	})
+	var syntaxErr *filter.SyntaxError
+	if errors.Is(err, product.ErrUnsupportedFacet) || errors.As(err, &syntaxErr) || isProjectionError(err) {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not fetch products", "err", err)
 		web.WriteError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
-	web.WriteJSON(w, http.StatusOK, response)
+	// pages shift when products are removed, so only the content based ETag is
+	// a safe validator here
+	web.WriteConditionalJSON(w, r, http.StatusOK, response, "", time.Time{})
 }
 
 // GetProduct godoc
 // @Tags         products
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true "id"
-// @Success      200  {object}  product.FetchByIdResponse
-// @Failure      400  {object}  web.errorResponse
-// @Failure      404  {object}  web.errorResponse
+// @Param        id                 path      string  true  "id"
+// @Param        fields             query     string  false "comma separated fields to return: id, name, price, created_at, updated_at"
+// @Param        expand             query     string  false "comma separated related resources to embed, none exist yet"
+// @Param        If-None-Match      header    string  false "ETag of the cached product"
+// @Param        If-Modified-Since  header    string  false "Last-Modified of the cached product"
+// @Success      200                {object}  product.FetchByIdResponse
+// @Success      304                "Not Modified"
+// @Failure      400                {object}  web.errorResponse
+// @Failure      404                {object}  web.errorResponse
 // @Router       /v1/products/{id} [get]
 // @Security Bearer
 func (h *ProductHandler) FetchById(w http.ResponseWriter, r *http.Request) {
@@ -89,21 +172,180 @@ func (h *ProductHandler) FetchById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.fetchByIdUseCase.Execute(r.Context(), product.FetchByIdRequest{Id: id})
+	fields := queryList(r, "fields")
+	response, err := h.fetchByIdUseCase.Execute(r.Context(), product.FetchByIdRequest{
+		Id:     id,
+		Fields: fields,
+		Expand: queryList(r, "expand"),
+	})
+	if isProjectionError(err) {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not fetch product", "err", err)
 		web.WriteError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
-	web.WriteJSON(w, http.StatusOK, response)
+	if len(fields) > 0 {
+		// a trimmed product is a different representation, and may lack what
+		// the validators are built from
+		web.WriteConditionalJSON(w, r, http.StatusOK, response, "", time.Time{})
+		return
+	}
+	etag := web.ETag(response.Id.String(), strconv.FormatInt(response.UpdatedAt.UnixNano(), 10))
+	web.WriteConditionalJSON(w, r, http.StatusOK, response, etag, response.UpdatedAt)
 }
 
-// Create Product godoc
+// Batch Fetch Products godoc
+// @Description  Like listing with ids, for lists too long for a URL.
 // @Tags         products
-// @Param        request  body      product.CreateRequest  true "payload"
-// @Success      201      {object}  product.CreateResponse
+// @Accept       json
+// @Produce      json
+// @Param        request  body      product.FetchByIdsRequest  true "up to 100 ids, and optionally fields and expand"
+// @Success      200      {object}  product.FetchByIdsResponse
 // @Failure      400      {object}  web.errorResponse
 // @Failure      422      {object}  web.errorResponse
+// @Router       /v1/products/batch [post]
+// @Security Bearer
+func (h *ProductHandler) FetchByIds(w http.ResponseWriter, r *http.Request) {
+	req, err := web.DecodeJSONBody[product.FetchByIdsRequest](r)
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.fetchByIds(w, r, req)
+}
+
+func (h *ProductHandler) fetchByIds(w http.ResponseWriter, r *http.Request, req product.FetchByIdsRequest) {
+	response, err := h.fetchByIdsUseCase.Execute(r.Context(), req)
+	if isProjectionError(err) {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not fetch products by ids", "err", err)
+		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		web.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+	web.WriteConditionalJSON(w, r, http.StatusOK, response, "", time.Time{})
+}
+
+// Search Products godoc
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        q              query     string  true  "words to match; quote a phrase, join alternatives with or, exclude with -word"
+// @Param        pageNumber     query     int     true  "pageNumber"
+// @Param        pageSize       query     int     true  "pageSize"
+// @Param        facets         query     string  false "comma separated facets to count, only price is supported"
+// @Param        fields         query     string  false "comma separated fields to return: id, name, price, created_at, updated_at"
+// @Param        expand         query     string  false "comma separated related resources to embed, none exist yet"
+// @Param        If-None-Match  header    string  false "ETag of the cached page"
+// @Success      200            {object}  product.SearchResponse
+// @Success      304            "Not Modified"
+// @Failure      400            {object}  web.errorResponse
+// @Failure      422            {object}  web.errorResponse
+// @Router       /v1/products/search [get]
+// @Security Bearer
+func (h *ProductHandler) Search(w http.ResponseWriter, r *http.Request) {
+	pageNumber, err := strconv.Atoi(r.URL.Query().Get("pageNumber"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.searchUseCase.Execute(r.Context(), product.SearchRequest{
+		Query:      r.URL.Query().Get("q"),
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		Facets:     queryList(r, "facets"),
+		Fields:     queryList(r, "fields"),
+		Expand:     queryList(r, "expand"),
+	})
+	if errors.Is(err, product.ErrUnsupportedFacet) || isProjectionError(err) {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not search products", "err", err)
+		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	web.WriteConditionalJSON(w, r, http.StatusOK, response, "", time.Time{})
+}
+
+// Suggest Products godoc
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Param        prefix         query     string  true  "what was typed so far, typos are tolerated"
+// @Param        limit          query     int     false "how many names to return, 1 to 25, default 10"
+// @Param        If-None-Match  header    string  false "ETag of the cached suggestions"
+// @Success      200            {object}  product.SuggestResponse
+// @Success      304            "Not Modified"
+// @Failure      400            {object}  web.errorResponse
+// @Failure      422            {object}  web.errorResponse
+// @Router       /v1/products/suggest [get]
+// @Security Bearer
+func (h *ProductHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	var limit int
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			web.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	response, err := h.suggestUseCase.Execute(r.Context(), product.SuggestRequest{
+		Prefix: r.URL.Query().Get("prefix"),
+		Limit:  limit,
+	})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not suggest products", "err", err)
+		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	web.WriteConditionalJSON(w, r, http.StatusOK, response, "", time.Time{})
+}
+
+// FetchDuplicates reports clusters of likely duplicate products for
+// cleanup. It is an operator endpoint, served on the admin port rather than
+// the public API.
+func (h *ProductHandler) FetchDuplicates(w http.ResponseWriter, r *http.Request) {
+	response, err := h.fetchDuplicatesUseCase.Execute(r.Context(), product.FetchDuplicatesRequest{})
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "could not fetch duplicate products", "err", err)
+		web.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+// Create Product godoc
+// @Tags         products
+// @Param        request         body      product.CreateRequest  true  "payload"
+// @Param        allowDuplicate  query     bool                   false "create the product even if it looks like an existing one"
+// @Success      201             {object}  product.CreateResponse
+// @Failure      400             {object}  web.errorResponse
+// @Failure      409             {object}  product.DuplicateResponse
+// @Failure      422             {object}  web.errorResponse
 // @Router       /v1/products [post]
 // @Security Bearer
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -112,9 +354,24 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 		web.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if raw := r.URL.Query().Get("allowDuplicate"); raw != "" {
+		if req.AllowDuplicate, err = strconv.ParseBool(raw); err != nil {
+			web.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	response, err := h.createUseCase.Execute(r.Context(), req)
+	var duplicateErr *product.DuplicateError
+	if errors.As(err, &duplicateErr) {
+		web.WriteJSON(w, http.StatusConflict, product.DuplicateResponse{
+			Message:    duplicateErr.Error(),
+			Duplicates: duplicateErr.Duplicates,
+		})
+		return
+	}
 	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not create product", "err", err)
 		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
@@ -139,6 +396,7 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	response, err := h.updateUseCase.Execute(r.Context(), req)
 	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not update product", "err", err)
 		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
@@ -163,9 +421,28 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	response, err := h.deleteUseCase.Execute(r.Context(), product.DeleteRequest{Id: id})
 	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not delete product", "err", err)
 		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
 
 	web.WriteJSON(w, http.StatusOK, response)
 }
+
+// isProjectionError reports whether err rejects the fields or expand
+// parameter, which is the client's mistake rather than a missing product.
+func isProjectionError(err error) bool {
+	return errors.Is(err, product.ErrUnknownField) || errors.Is(err, product.ErrUnknownExpansion)
+}
+
+// queryList splits the comma separated values of the key query parameter.
+func queryList(r *http.Request, key string) []string {
+	var out []string
+	for _, value := range strings.Split(r.URL.Query().Get(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, value)
+		}
+	}
+
+	return out
+}
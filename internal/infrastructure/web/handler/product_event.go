@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/infrastructure/event"
+	"github.com/rosset7i/product_crud/internal/infrastructure/web"
+)
+
+type ProductEventHandler struct {
+	stream    *event.Stream
+	heartbeat time.Duration
+	logger    *slog.Logger
+}
+
+func NewProductEventHandler(stream *event.Stream, heartbeat time.Duration, logger *slog.Logger) *ProductEventHandler {
+	return &ProductEventHandler{
+		stream:    stream,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+// Stream Product Events godoc
+// @Summary      Server-Sent Events stream of product changes
+// @Description  Reconnecting with Last-Event-ID replays the events missed since then. When they are no longer known, a `resync` event is sent instead and the client should refetch the products it follows.
+// @Tags         products
+// @Produce      text/event-stream
+// @Param        ids            query     string  false "comma separated product ids to follow"
+// @Param        Last-Event-ID  header    string  false "resume after this event id"
+// @Success      200            {object}  event.Payload
+// @Failure      400            {object}  web.errorResponse
+// @Router       /v1/products/events [get]
+// @Security Bearer
+func (h *ProductEventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseProductFilter(r.URL.Query().Get("ids"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = r.URL.Query().Get("lastEventId")
+	}
+	var lastSeq uint64
+	if lastEventId != "" {
+		lastSeq, err = strconv.ParseUint(lastEventId, 10, 64)
+		if err != nil {
+			web.WriteError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	// the stream outlives the server write timeout
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	sub, replay := h.stream.Subscribe(lastSeq, filter)
+	defer h.stream.Unsubscribe(sub)
+	h.logger.DebugContext(r.Context(), "event stream opened",
+		"last_event_id", lastSeq, "replayed", len(replay.Messages), "complete", replay.Complete)
+	defer h.logger.DebugContext(r.Context(), "event stream closed")
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if !replay.Complete {
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: resync\ndata: {}\n\n", replay.Seq); err != nil {
+			return
+		}
+	}
+	for _, m := range replay.Messages {
+		if err := writeEvent(w, m); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case m, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, m); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, m event.Message) error {
+	data, err := json.Marshal(event.NewPayload(m.Event))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", m.Seq, m.Event.Type, data)
+	return err
+}
+
+func parseProductFilter(raw string) (event.Filter, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0)
+	for _, value := range strings.Split(raw, ",") {
+		id, err := uuid.Parse(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return func(e *domain.Event) bool {
+		return slices.Contains(ids, e.ProductId)
+	}, nil
+}
@@ -1,21 +1,29 @@
 package handler
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/rosset7i/product_crud/internal/infrastructure/web"
+	"github.com/rosset7i/product_crud/internal/usecase"
 	"github.com/rosset7i/product_crud/internal/usecase/user"
 )
 
 type UserHandler struct {
-	registerUseCase *user.RegisterUseCase
-	loginUseCase    *user.LoginUseCase
+	registerUseCase usecase.UseCase[user.RegisterRequest, user.RegisterResponse]
+	loginUseCase    usecase.UseCase[user.LoginRequest, user.LoginResponse]
+	logger          *slog.Logger
 }
 
-func NewUserHandler(registerUseCase *user.RegisterUseCase, loginUseCase *user.LoginUseCase) *UserHandler {
+func NewUserHandler(
+	registerUseCase usecase.UseCase[user.RegisterRequest, user.RegisterResponse],
+	loginUseCase usecase.UseCase[user.LoginRequest, user.LoginResponse],
+	logger *slog.Logger,
+) *UserHandler {
 	return &UserHandler{
 		registerUseCase: registerUseCase,
 		loginUseCase:    loginUseCase,
+		logger:          logger,
 	}
 }
 
@@ -34,6 +42,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	response, err := h.registerUseCase.Execute(r.Context(), req)
 	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not register user", "err", err)
 		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
@@ -57,6 +66,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	response, err := h.loginUseCase.Execute(r.Context(), req)
 	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not log in", "err", err)
 		web.WriteError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
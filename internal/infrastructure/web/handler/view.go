@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/infrastructure/web"
+	"github.com/rosset7i/product_crud/internal/usecase"
+	"github.com/rosset7i/product_crud/internal/usecase/view"
+)
+
+type ViewHandler struct {
+	fetchAllUseCase  usecase.UseCase[view.FetchAllRequest, view.FetchAllResponse]
+	fetchByIdUseCase usecase.UseCase[view.FetchByIdRequest, view.FetchByIdResponse]
+	createUseCase    usecase.UseCase[view.CreateRequest, view.CreateResponse]
+	updateUseCase    usecase.UseCase[view.UpdateRequest, view.UpdateResponse]
+	deleteUseCase    usecase.UseCase[view.DeleteRequest, view.DeleteResponse]
+	logger           *slog.Logger
+}
+
+func NewViewHandler(
+	fetchAllUseCase usecase.UseCase[view.FetchAllRequest, view.FetchAllResponse],
+	fetchByIdUseCase usecase.UseCase[view.FetchByIdRequest, view.FetchByIdResponse],
+	createUseCase usecase.UseCase[view.CreateRequest, view.CreateResponse],
+	updateUseCase usecase.UseCase[view.UpdateRequest, view.UpdateResponse],
+	deleteUseCase usecase.UseCase[view.DeleteRequest, view.DeleteResponse],
+	logger *slog.Logger,
+) *ViewHandler {
+	return &ViewHandler{
+		fetchAllUseCase:  fetchAllUseCase,
+		fetchByIdUseCase: fetchByIdUseCase,
+		createUseCase:    createUseCase,
+		updateUseCase:    updateUseCase,
+		deleteUseCase:    deleteUseCase,
+		logger:           logger,
+	}
+}
+
+// List Views godoc
+// @Description  Views the user owns and views other users shared with them, by name. Who a view is shared with is only listed to its owner.
+// @Tags         views
+// @Produce      json
+// @Success      200  {object}  view.FetchAllResponse
+// @Failure      401  {object}  web.errorResponse
+// @Failure      422  {object}  web.errorResponse
+// @Router       /v1/views [get]
+// @Security Bearer
+func (h *ViewHandler) FetchAll(w http.ResponseWriter, r *http.Request) {
+	userId, err := web.UserId(r)
+	if err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.fetchAllUseCase.Execute(r.Context(), view.FetchAllRequest{UserId: userId})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not fetch views", "err", err)
+		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetView godoc
+// @Tags         views
+// @Produce      json
+// @Param        id   path      string  true "id"
+// @Success      200  {object}  view.FetchByIdResponse
+// @Failure      400  {object}  web.errorResponse
+// @Failure      401  {object}  web.errorResponse
+// @Failure      404  {object}  web.errorResponse
+// @Router       /v1/views/{id} [get]
+// @Security Bearer
+func (h *ViewHandler) FetchById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userId, err := web.UserId(r)
+	if err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.fetchByIdUseCase.Execute(r.Context(), view.FetchByIdRequest{Id: id, UserId: userId})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not fetch view", "err", err)
+		web.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+// Create View godoc
+// @Tags         views
+// @Param        request  body      view.CreateRequest  true "payload"
+// @Success      201      {object}  view.CreateResponse
+// @Failure      400      {object}  web.errorResponse
+// @Failure      401      {object}  web.errorResponse
+// @Failure      422      {object}  web.errorResponse
+// @Router       /v1/views [post]
+// @Security Bearer
+func (h *ViewHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, err := web.DecodeJSONBody[view.CreateRequest](r)
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.UserId, err = web.UserId(r); err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.createUseCase.Execute(r.Context(), req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not create view", "err", err)
+		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusCreated, response)
+}
+
+// UpdateView godoc
+// @Description  Only the owner can change a view, including who it is shared with.
+// @Tags         views
+// @Param        request  body      view.UpdateRequest  true "payload"
+// @Success      200      {object}  view.UpdateResponse
+// @Failure      400      {object}  web.errorResponse
+// @Failure      401      {object}  web.errorResponse
+// @Failure      403      {object}  web.errorResponse
+// @Failure      404      {object}  web.errorResponse
+// @Failure      422      {object}  web.errorResponse
+// @Router       /v1/views [put]
+// @Security Bearer
+func (h *ViewHandler) Update(w http.ResponseWriter, r *http.Request) {
+	req, err := web.DecodeJSONBody[view.UpdateRequest](r)
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.UserId, err = web.UserId(r); err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.updateUseCase.Execute(r.Context(), req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not update view", "err", err)
+		web.WriteError(w, viewErrorStatus(err), err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+// DeleteView godoc
+// @Tags         views
+// @Param        id   query     string  true "id"
+// @Success      200  {object}  view.DeleteResponse
+// @Failure      400  {object}  web.errorResponse
+// @Failure      401  {object}  web.errorResponse
+// @Failure      403  {object}  web.errorResponse
+// @Failure      404  {object}  web.errorResponse
+// @Failure      422  {object}  web.errorResponse
+// @Router       /v1/views [delete]
+// @Security Bearer
+func (h *ViewHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userId, err := web.UserId(r)
+	if err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.deleteUseCase.Execute(r.Context(), view.DeleteRequest{Id: id, UserId: userId})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not delete view", "err", err)
+		web.WriteError(w, viewErrorStatus(err), err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+func viewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrViewNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, view.ErrNotOwner):
+		return http.StatusForbidden
+	}
+
+	return http.StatusUnprocessableEntity
+}
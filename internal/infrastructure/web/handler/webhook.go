@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/infrastructure/web"
+	"github.com/rosset7i/product_crud/internal/usecase"
+	"github.com/rosset7i/product_crud/internal/usecase/webhook"
+)
+
+type WebhookHandler struct {
+	fetchAllUseCase        usecase.UseCase[webhook.FetchAllRequest, webhook.FetchAllResponse]
+	fetchByIdUseCase       usecase.UseCase[webhook.FetchByIdRequest, webhook.FetchByIdResponse]
+	createUseCase          usecase.UseCase[webhook.CreateRequest, webhook.CreateResponse]
+	updateUseCase          usecase.UseCase[webhook.UpdateRequest, webhook.UpdateResponse]
+	deleteUseCase          usecase.UseCase[webhook.DeleteRequest, webhook.DeleteResponse]
+	fetchDeliveriesUseCase usecase.UseCase[webhook.FetchDeliveriesRequest, webhook.FetchDeliveriesResponse]
+	redeliverUseCase       usecase.UseCase[webhook.RedeliverRequest, webhook.RedeliverResponse]
+	logger                 *slog.Logger
+}
+
+func NewWebhookHandler(
+	fetchAllUseCase usecase.UseCase[webhook.FetchAllRequest, webhook.FetchAllResponse],
+	fetchByIdUseCase usecase.UseCase[webhook.FetchByIdRequest, webhook.FetchByIdResponse],
+	createUseCase usecase.UseCase[webhook.CreateRequest, webhook.CreateResponse],
+	updateUseCase usecase.UseCase[webhook.UpdateRequest, webhook.UpdateResponse],
+	deleteUseCase usecase.UseCase[webhook.DeleteRequest, webhook.DeleteResponse],
+	fetchDeliveriesUseCase usecase.UseCase[webhook.FetchDeliveriesRequest, webhook.FetchDeliveriesResponse],
+	redeliverUseCase usecase.UseCase[webhook.RedeliverRequest, webhook.RedeliverResponse],
+	logger *slog.Logger,
+) *WebhookHandler {
+	return &WebhookHandler{
+		fetchAllUseCase:        fetchAllUseCase,
+		fetchByIdUseCase:       fetchByIdUseCase,
+		createUseCase:          createUseCase,
+		updateUseCase:          updateUseCase,
+		deleteUseCase:          deleteUseCase,
+		fetchDeliveriesUseCase: fetchDeliveriesUseCase,
+		redeliverUseCase:       redeliverUseCase,
+		logger:                 logger,
+	}
+}
+
+// List Webhooks godoc
+// @Description  Webhooks the user created, oldest first.
+// @Tags         webhooks
+// @Produce      json
+// @Success      200  {object}  webhook.FetchAllResponse
+// @Failure      401  {object}  web.errorResponse
+// @Failure      422  {object}  web.errorResponse
+// @Router       /v1/webhooks [get]
+// @Security Bearer
+func (h *WebhookHandler) FetchAll(w http.ResponseWriter, r *http.Request) {
+	userId, err := web.UserId(r)
+	if err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.fetchAllUseCase.Execute(r.Context(), webhook.FetchAllRequest{UserId: userId})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not fetch webhooks", "err", err)
+		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetWebhook godoc
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      string  true "id"
+// @Success      200  {object}  webhook.FetchByIdResponse
+// @Failure      400  {object}  web.errorResponse
+// @Failure      401  {object}  web.errorResponse
+// @Failure      404  {object}  web.errorResponse
+// @Router       /v1/webhooks/{id} [get]
+// @Security Bearer
+func (h *WebhookHandler) FetchById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userId, err := web.UserId(r)
+	if err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.fetchByIdUseCase.Execute(r.Context(), webhook.FetchByIdRequest{Id: id, UserId: userId})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not fetch webhook", "err", err)
+		web.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+// Create Webhook godoc
+// @Tags         webhooks
+// @Param        request  body      webhook.CreateRequest  true "payload"
+// @Success      201      {object}  webhook.CreateResponse
+// @Failure      400      {object}  web.errorResponse
+// @Failure      401      {object}  web.errorResponse
+// @Failure      422      {object}  web.errorResponse
+// @Router       /v1/webhooks [post]
+// @Security Bearer
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, err := web.DecodeJSONBody[webhook.CreateRequest](r)
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.UserId, err = web.UserId(r); err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.createUseCase.Execute(r.Context(), req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not create webhook", "err", err)
+		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusCreated, response)
+}
+
+// UpdateWebhook godoc
+// @Tags         webhooks
+// @Param        request  body      webhook.UpdateRequest  true "payload"
+// @Success      200      {object}  webhook.UpdateResponse
+// @Failure      400      {object}  web.errorResponse
+// @Failure      401      {object}  web.errorResponse
+// @Failure      422      {object}  web.errorResponse
+// @Router       /v1/webhooks [put]
+// @Security Bearer
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	req, err := web.DecodeJSONBody[webhook.UpdateRequest](r)
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.UserId, err = web.UserId(r); err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.updateUseCase.Execute(r.Context(), req)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not update webhook", "err", err)
+		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+// DeleteWebhook godoc
+// @Tags         webhooks
+// @Param        id   query     string  true "id"
+// @Success      200  {object}  webhook.DeleteResponse
+// @Failure      400  {object}  web.errorResponse
+// @Failure      401  {object}  web.errorResponse
+// @Failure      422  {object}  web.errorResponse
+// @Router       /v1/webhooks [delete]
+// @Security Bearer
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userId, err := web.UserId(r)
+	if err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.deleteUseCase.Execute(r.Context(), webhook.DeleteRequest{Id: id, UserId: userId})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not delete webhook", "err", err)
+		web.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+// List Webhook Deliveries godoc
+// @Tags         webhooks
+// @Produce      json
+// @Param        id          path      string  true "id"
+// @Param        pageNumber  query     int     true "pageNumber"
+// @Param        pageSize    query     int     true "pageSize"
+// @Success      200         {object}  webhook.FetchDeliveriesResponse
+// @Failure      400         {object}  web.errorResponse
+// @Failure      401         {object}  web.errorResponse
+// @Failure      404         {object}  web.errorResponse
+// @Router       /v1/webhooks/{id}/deliveries [get]
+// @Security Bearer
+func (h *WebhookHandler) FetchDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageNumber, err := strconv.Atoi(r.URL.Query().Get("pageNumber"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userId, err := web.UserId(r)
+	if err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.fetchDeliveriesUseCase.Execute(r.Context(), webhook.FetchDeliveriesRequest{
+		WebhookId:  id,
+		UserId:     userId,
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not fetch webhook deliveries", "err", err)
+		web.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusOK, response)
+}
+
+// Redeliver Webhook Delivery godoc
+// @Tags         webhooks
+// @Produce      json
+// @Param        id          path      string  true "id"
+// @Param        deliveryId  path      string  true "deliveryId"
+// @Success      202         {object}  webhook.RedeliverResponse
+// @Failure      400         {object}  web.errorResponse
+// @Failure      401         {object}  web.errorResponse
+// @Failure      404         {object}  web.errorResponse
+// @Router       /v1/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+// @Security Bearer
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	deliveryId, err := uuid.Parse(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		web.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userId, err := web.UserId(r)
+	if err != nil {
+		web.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response, err := h.redeliverUseCase.Execute(r.Context(), webhook.RedeliverRequest{
+		WebhookId:  id,
+		DeliveryId: deliveryId,
+		UserId:     userId,
+	})
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "could not redeliver webhook", "err", err)
+		web.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	web.WriteJSON(w, http.StatusAccepted, response)
+}
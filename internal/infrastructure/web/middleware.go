@@ -0,0 +1,118 @@
+package web
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth"
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/infrastructure/logging"
+)
+
+func CacheControl(value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if value != "" {
+				w.Header().Set("Cache-Control", value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequestLogger tags every record logged while serving the request with the
+// request id and route pattern, then logs the outcome of the request.
+// It expects middleware.RequestID to run first.
+func RequestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx := logging.WithFields(r.Context())
+			logging.AddFields(ctx,
+				slog.String("request_id", middleware.GetReqID(ctx)),
+				slog.Any("route", routePattern{chi.RouteContext(ctx)}),
+			)
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+
+			logger.LogAttrs(ctx, level, "request completed",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Duration("latency", time.Since(start)),
+			)
+		})
+	}
+}
+
+// UserIdentity adds the authenticated user to the request log fields. It
+// must run after jwtauth.Authenticator.
+func UserIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, claims, err := jwtauth.FromContext(r.Context()); err == nil {
+			if sub, ok := claims["sub"].(string); ok {
+				logging.AddFields(r.Context(), slog.String("user_id", sub))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UserId is the id of the authenticated user, the subject of the JWT.
+func UserId(r *http.Request) (uuid.UUID, error) {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return uuid.Nil, err
+	}
+	sub, _ := claims["sub"].(string)
+
+	return uuid.Parse(sub)
+}
+
+// ReadYourWrites scopes read replica pinning to the request and, once
+// authenticated, to the user, so a user keeps reading their own writes.
+// withSession attaches the pinning session for a caller key to the context.
+// It must run after jwtauth.Authenticator.
+func ReadYourWrites(withSession func(ctx context.Context, key string) context.Context) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var key string
+			if _, claims, err := jwtauth.FromContext(r.Context()); err == nil {
+				key, _ = claims["sub"].(string)
+			}
+			next.ServeHTTP(w, r.WithContext(withSession(r.Context(), key)))
+		})
+	}
+}
+
+// routePattern resolves lazily so that records logged before routing
+// finished still report the matched pattern.
+type routePattern struct {
+	rctx *chi.Context
+}
+
+func (p routePattern) LogValue() slog.Value {
+	if p.rctx == nil {
+		return slog.StringValue("")
+	}
+
+	return slog.StringValue(p.rctx.RoutePattern())
+}
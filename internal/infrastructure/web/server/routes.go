@@ -8,13 +8,23 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/go-chi/jwtauth"
 	"github.com/rosset7i/product_crud/config"
+	"github.com/rosset7i/product_crud/internal/infrastructure/database"
+	"github.com/rosset7i/product_crud/internal/infrastructure/tracing"
+	"github.com/rosset7i/product_crud/internal/infrastructure/web"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 func (s *Server) MapHandlers(c *config.Conf) http.Handler {
 	r := chi.NewRouter()
 
-	r.Use(middleware.Logger)
+	r.Use(middleware.RequestID)
+	r.Use(web.RequestLogger(s.logger))
+	if s.tracing.Enabled() {
+		r.Use(tracing.Middleware)
+	}
+	if s.metrics != nil {
+		r.Use(s.metrics.Middleware)
+	}
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
@@ -24,6 +34,10 @@ func (s *Server) MapHandlers(c *config.Conf) http.Handler {
 	}))
 	r.Use(middleware.Recoverer)
 
+	healthHandler := s.container.HealthHandler
+	r.Get("/healthz", healthHandler.Liveness)
+	r.Get("/readyz", healthHandler.Readiness)
+
 	r.Route("/v1", func(r chi.Router) {
 		userHandler := s.container.UserHandler
 		r.Route("/users", func(r chi.Router) {
@@ -32,15 +46,48 @@ func (s *Server) MapHandlers(c *config.Conf) http.Handler {
 		})
 
 		productHandler := s.container.ProductHandler
+		productEventHandler := s.container.ProductEventHandler
 		r.Route("/products", func(r chi.Router) {
 			r.Use(jwtauth.Verifier(c.Auth.JwtAuth))
 			r.Use(jwtauth.Authenticator)
-			r.Get("/", productHandler.FetchPaged)
-			r.Get("/{id}", productHandler.FetchById)
+			r.Use(web.UserIdentity)
+			r.Use(web.ReadYourWrites(database.WithSession))
+			r.With(web.CacheControl(c.Server.CacheControl)).Get("/", productHandler.FetchPaged)
+			r.Get("/events", productEventHandler.Stream)
+			r.With(web.CacheControl(c.Server.CacheControl)).Get("/search", productHandler.Search)
+			r.With(web.CacheControl(c.Server.SuggestCacheControl)).Get("/suggest", productHandler.Suggest)
+			r.Post("/batch", productHandler.FetchByIds)
+			r.With(web.CacheControl(c.Server.CacheControl)).Get("/{id}", productHandler.FetchById)
 			r.Post("/", productHandler.Create)
 			r.Put("/", productHandler.Update)
 			r.Delete("/", productHandler.Delete)
 		})
+
+		webhookHandler := s.container.WebhookHandler
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(jwtauth.Verifier(c.Auth.JwtAuth))
+			r.Use(jwtauth.Authenticator)
+			r.Use(web.UserIdentity)
+			r.Get("/", webhookHandler.FetchAll)
+			r.Get("/{id}", webhookHandler.FetchById)
+			r.Post("/", webhookHandler.Create)
+			r.Put("/", webhookHandler.Update)
+			r.Delete("/", webhookHandler.Delete)
+			r.Get("/{id}/deliveries", webhookHandler.FetchDeliveries)
+			r.Post("/{id}/deliveries/{deliveryId}/redeliver", webhookHandler.Redeliver)
+		})
+
+		viewHandler := s.container.ViewHandler
+		r.Route("/views", func(r chi.Router) {
+			r.Use(jwtauth.Verifier(c.Auth.JwtAuth))
+			r.Use(jwtauth.Authenticator)
+			r.Use(web.UserIdentity)
+			r.Get("/", viewHandler.FetchAll)
+			r.Get("/{id}", viewHandler.FetchById)
+			r.Post("/", viewHandler.Create)
+			r.Put("/", viewHandler.Update)
+			r.Delete("/", viewHandler.Delete)
+		})
 	})
 	r.Get("/docs/*", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:7000/docs/doc.json"),
@@ -48,3 +95,19 @@ func (s *Server) MapHandlers(c *config.Conf) http.Handler {
 
 	return r
 }
+
+// MapAdminHandlers serves the operational endpoints on the admin port, away
+// from the public API.
+func (s *Server) MapAdminHandlers(c *config.Conf) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middleware.Recoverer)
+	if s.metrics != nil {
+		r.Method(http.MethodGet, "/metrics", s.metrics.Handler())
+	}
+	if c.Duplicates.ReportEnabled {
+		r.Get("/v1/products/duplicates", s.container.ProductHandler.FetchDuplicates)
+	}
+
+	return r
+}
@@ -2,29 +2,112 @@ package server
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rosset7i/product_crud/config"
 	"github.com/rosset7i/product_crud/internal/infrastructure/database"
+	"github.com/rosset7i/product_crud/internal/infrastructure/lifecycle"
+	"github.com/rosset7i/product_crud/internal/infrastructure/logging"
+	"github.com/rosset7i/product_crud/internal/infrastructure/metrics"
+	"github.com/rosset7i/product_crud/internal/infrastructure/sqlite"
+	"github.com/rosset7i/product_crud/internal/infrastructure/tracing"
 )
 
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
+// Options are the command line choices of cmd/api.
+type Options struct {
+	// Storage is StoragePostgres, StorageSQLite or StorageMemory.
+	Storage string
+	// Seed is a JSON file loaded into memory storage on start, if set.
+	Seed string
+}
+
 type Server struct {
 	c         *config.Conf
+	opts      Options
 	db        *pgxpool.Pool
+	cluster   *database.Cluster
+	sqlite    *sql.DB
+	logger    *slog.Logger
+	metrics   *metrics.Metrics
+	tracing   *tracing.Tracing
 	container *Container
 }
 
-func NewServer(c *config.Conf) *Server {
+func NewServer(c *config.Conf, opts Options) *Server {
+	logger := logging.New(os.Stdout, &c.Log)
+	slog.SetDefault(logger)
+
+	t, err := tracing.New(context.Background(), &c.Tracing)
+	if err != nil {
+		logger.Error("could not set up tracing", "err", err)
+		os.Exit(1)
+	}
+
+	var (
+		db      *pgxpool.Pool
+		cluster *database.Cluster
+		lite    *sql.DB
+	)
+	switch opts.Storage {
+	case StoragePostgres:
+		tracers := []pgx.QueryTracer{database.NewQueryLogger(&c.DB, logger)}
+		if t.Enabled() {
+			tracers = append(tracers, tracing.NewQueryTracer())
+		}
+		db, err = database.New(context.Background(), &c.DB, logger, tracers...)
+		if err != nil {
+			logger.Error("could not connect to database", "err", err)
+			os.Exit(1)
+		}
+		cluster, err = database.NewCluster(db, &c.DB, logger, tracers...)
+		if err != nil {
+			logger.Error("could not set up read replicas", "err", err)
+			os.Exit(1)
+		}
+	case StorageSQLite:
+		lite, err = sqlite.Open(context.Background(), &c.DB, logger)
+		if err != nil {
+			logger.Error("could not open database", "err", err)
+			os.Exit(1)
+		}
+	case StorageMemory:
+		logger.Warn("using in-memory storage, data is lost on exit")
+	default:
+		logger.Error("unknown storage", "storage", opts.Storage)
+		os.Exit(1)
+	}
+
+	var m *metrics.Metrics
+	if c.Server.MetricsEnabled {
+		m = metrics.New()
+	}
+
 	return &Server{
-		c:  c,
-		db: database.New(context.Background(), &c.DB),
+		c:       c,
+		opts:    opts,
+		db:      db,
+		cluster: cluster,
+		sqlite:  lite,
+		logger:  logger,
+		metrics: m,
+		tracing: t,
 	}
 }
 
@@ -37,30 +120,146 @@ func (s *Server) Run() {
 		ReadTimeout:  s.c.Server.TimeoutRead,
 		WriteTimeout: s.c.Server.TimeoutWrite,
 		IdleTimeout:  s.c.Server.TimeoutIdle,
+		ErrorLog:     slog.NewLogLogger(s.logger.Handler(), slog.LevelError),
 	}
+	// live streams never end on their own, so they are closed as soon as the
+	// drain starts
+	server.RegisterOnShutdown(s.container.Stream.Close)
 
-	go func() {
-		log.Printf("Starting server at :%d", s.c.Server.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal(err)
-		}
-	}()
+	failed := make(chan error, 2)
+	lc := s.lifecycle(server, failed)
+	if err := lc.Start(context.Background()); err != nil {
+		s.logger.Error("could not start", "err", err)
+		os.Exit(1)
+	}
 
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGTERM, syscall.SIGINT)
 
-	<-shutdown
+	exitCode := 0
+	select {
+	case <-shutdown:
+	case err := <-failed:
+		s.logger.Error("server stopped unexpectedly", "err", err)
+		exitCode = 1
+	}
+
+	s.logger.Info("shutting down gracefully, press Ctrl+C again to force")
+	go func() {
+		<-shutdown
+		s.logger.Error("forced shutdown")
+		os.Exit(1)
+	}()
 
-	log.Println("shutting down gracefully, press Ctrl+C again to force")
+	if err := lc.Stop(context.Background()); err != nil {
+		s.logger.Error("graceful shutdown incomplete", "err", err)
+		os.Exit(1)
+	}
 
-	s.db.Close()
+	s.logger.Info("graceful shutdown complete")
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// lifecycle registers the components in start order. They stop in reverse:
+// stop taking traffic, drain HTTP, stop background workers, flush
+// telemetry and finally close the pool.
+func (s *Server) lifecycle(server *http.Server, failed chan<- error) *lifecycle.Manager {
+	lc := lifecycle.New(s.logger)
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	if s.db != nil {
+		lc.Append(lifecycle.Hook{
+			Name: "database",
+			OnStart: func(context.Context) error {
+				s.cluster.Start()
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				s.cluster.Close()
+				s.db.Close()
+				return nil
+			},
+			Timeout: s.c.Shutdown.DatabaseTimeout,
+		})
+	}
+	if s.sqlite != nil {
+		lc.Append(lifecycle.Hook{
+			Name: "database",
+			OnStop: func(context.Context) error {
+				return s.sqlite.Close()
+			},
+			Timeout: s.c.Shutdown.DatabaseTimeout,
+		})
+	}
+	lc.Append(lifecycle.Hook{
+		Name:    "telemetry",
+		OnStop:  s.tracing.Shutdown,
+		Timeout: s.c.Shutdown.TelemetryTimeout,
+	})
+	lc.Append(lifecycle.Hook{
+		Name: "workers",
+		OnStart: func(ctx context.Context) error {
+			if s.container.Listener != nil {
+				s.container.Listener.Start()
+			}
+			return s.container.Dispatcher.Resume(ctx)
+		},
+		OnStop: func(context.Context) error {
+			if s.container.Listener != nil {
+				s.container.Listener.Close()
+			}
+			s.container.Dispatcher.Close()
+			return nil
+		},
+		Timeout: s.c.Shutdown.WorkersTimeout,
+	})
+	if s.metrics != nil || s.c.Duplicates.ReportEnabled {
+		admin := &http.Server{
+			Addr:     fmt.Sprintf(":%d", s.c.Server.AdminPort),
+			Handler:  s.MapAdminHandlers(s.c),
+			ErrorLog: slog.NewLogLogger(s.logger.Handler(), slog.LevelError),
+		}
+		lc.Append(s.serve("admin server", admin, failed))
 	}
+	lc.Append(s.serve("server", server, failed))
+	lc.Append(lifecycle.Hook{
+		Name: "traffic",
+		OnStop: func(ctx context.Context) error {
+			// fail readiness first and give load balancers time to notice
+			// before anything stops serving
+			s.container.Health.Drain()
+			select {
+			case <-time.After(s.c.Server.DrainDelay):
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
 
-	log.Println("Graceful shutdown complete.")
+	return lc
+}
+
+// serve binds the listener while starting, so a taken port fails the start,
+// and reports later failures on failed.
+func (s *Server) serve(name string, server *http.Server, failed chan<- error) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			ln, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+
+			s.logger.Info("starting "+name, "addr", server.Addr)
+			go func() {
+				if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					failed <- fmt.Errorf("%s: %w", name, err)
+				}
+			}()
+			return nil
+		},
+		OnStop:  server.Shutdown,
+		Timeout: s.c.Shutdown.HTTPTimeout,
+	}
 }
@@ -1,37 +1,244 @@
 package server
 
 import (
+	"context"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/infrastructure/cache"
 	"github.com/rosset7i/product_crud/internal/infrastructure/database"
+	"github.com/rosset7i/product_crud/internal/infrastructure/event"
+	"github.com/rosset7i/product_crud/internal/infrastructure/health"
+	"github.com/rosset7i/product_crud/internal/infrastructure/memory"
+	"github.com/rosset7i/product_crud/internal/infrastructure/sqlite"
+	"github.com/rosset7i/product_crud/internal/infrastructure/tracing"
 	"github.com/rosset7i/product_crud/internal/infrastructure/web/handler"
+	"github.com/rosset7i/product_crud/internal/infrastructure/webhook"
+	"github.com/rosset7i/product_crud/internal/usecase"
 	"github.com/rosset7i/product_crud/internal/usecase/product"
 	"github.com/rosset7i/product_crud/internal/usecase/user"
+	viewUseCase "github.com/rosset7i/product_crud/internal/usecase/view"
+	webhookUseCase "github.com/rosset7i/product_crud/internal/usecase/webhook"
+	"github.com/rosset7i/product_crud/migrations"
 )
 
 type Container struct {
-	UserHandler    *handler.UserHandler
-	ProductHandler *handler.ProductHandler
+	UserHandler         *handler.UserHandler
+	ProductHandler      *handler.ProductHandler
+	ProductEventHandler *handler.ProductEventHandler
+	WebhookHandler      *handler.WebhookHandler
+	ViewHandler         *handler.ViewHandler
+	HealthHandler       *handler.HealthHandler
+	Health              *health.Checker
+	Dispatcher          *webhook.Dispatcher
+	Stream              *event.Stream
+	Listener            *database.Listener
 }
 
 func (s *Server) init() {
 	// repositories
-	userRepository := database.NewUserRepository(s.db)
-	productRepository := database.NewProductRepository(s.db)
+	var (
+		userRepository            domain.UserRepository
+		productRepository         domain.ProductRepository
+		webhookRepository         domain.WebhookRepository
+		webhookDeliveryRepository domain.WebhookDeliveryRepository
+		viewRepository            domain.ViewRepository
+		txManager                 domain.TxManager
+	)
+	switch s.opts.Storage {
+	case StoragePostgres:
+		userRepository = database.NewUserRepository(s.db, s.logger)
+		productRepository = database.NewProductRepository(s.cluster, s.c.DB.SearchLanguage, s.logger)
+		webhookRepository = database.NewWebhookRepository(s.db, s.logger)
+		webhookDeliveryRepository = database.NewWebhookDeliveryRepository(s.db, s.logger)
+		viewRepository = database.NewViewRepository(s.db, s.logger)
+		txManager = database.NewTxManager(s.db, s.logger)
+	case StorageSQLite:
+		userRepository = sqlite.NewUserRepository(s.sqlite, s.logger)
+		productRepository = sqlite.NewProductRepository(s.sqlite, s.logger)
+		webhookRepository = sqlite.NewWebhookRepository(s.sqlite, s.logger)
+		webhookDeliveryRepository = sqlite.NewWebhookDeliveryRepository(s.sqlite, s.logger)
+		viewRepository = sqlite.NewViewRepository(s.sqlite, s.logger)
+		txManager = sqlite.NewTxManager(s.sqlite, s.logger)
+	case StorageMemory:
+		userRepository = memory.NewUserRepository()
+		productRepository = memory.NewProductRepository()
+		webhookRepository = memory.NewWebhookRepository()
+		webhookDeliveryRepository = memory.NewWebhookDeliveryRepository()
+		viewRepository = memory.NewViewRepository()
+		txManager = memory.NewTxManager()
+	}
+	if s.opts.Seed != "" {
+		if s.opts.Storage == StorageMemory {
+			s.seed(userRepository, productRepository)
+		} else {
+			s.logger.Warn("ignoring seed, it only applies to memory storage", "path", s.opts.Seed)
+		}
+	}
+	var productCache *cache.ProductRepository
+	if s.c.Cache.Enabled {
+		productCache = cache.NewProductRepository(productRepository, txManager, s.c.Cache.Size, s.c.Cache.TTL)
+		productRepository = productCache
+	}
+
+	// events
+	dispatcher := webhook.NewDispatcher(webhookRepository, webhookDeliveryRepository, &s.c.Webhook, s.logger)
+	stream := event.NewStream(s.c.Events.ReplaySize, s.c.Events.ClientBuffer)
+	bus := event.NewBus(dispatcher, stream)
+	var listener *database.Listener
+	if s.c.Events.Notify && s.db == nil {
+		s.logger.Warn("EVENTS_NOTIFY needs Postgres, keeping events local to this instance")
+	}
+	if s.c.Events.Notify && s.db != nil {
+		local := []domain.EventPublisher{stream}
+		if productCache != nil {
+			local = append(local, productCache)
+		}
+		// webhooks stay local to the instance that made the change, while
+		// per-instance subscribers are fed by every replica through Postgres
+		bus = event.NewBus(dispatcher, database.NewNotifier(s.db, s.logger))
+		listener = database.NewListener(s.db, event.NewBus(local...), s.logger)
+	}
+
+	// observability
+	var observers, loginObservers []usecase.Observer
+	if s.tracing.Enabled() {
+		observers = append(observers, tracing.NewObserver())
+	}
+	loginObservers = observers
+	if s.metrics != nil {
+		if s.db != nil {
+			s.metrics.RegisterPool(s.db)
+		}
+		if productCache != nil {
+			s.metrics.RegisterCache(productCache.Stats)
+		}
+		observers = append(observers, s.metrics)
+		loginObservers = append(slices.Clone(observers), s.metrics.LoginObserver())
+	}
 
 	// use cases
-	registerUseCase := user.NewRegisterUseCase(userRepository)
-	loginUseCase := user.NewLoginUseCase(userRepository, s.c.Auth.JwtAuth, s.c.Auth.JwtExpiresIn)
-	fetchPagedProductsUseCase := product.NewFetchPagedProductsUseCase(productRepository)
-	fetchByIdUseCase := product.NewFetchByIdUseCase(productRepository)
-	createUseCase := product.NewCreateUseCase(productRepository)
-	updateUseCase := product.NewUpdateUseCase(productRepository)
-	deleteUseCase := product.NewDeleteUseCase(productRepository)
+	priceBounds := domain.PriceBounds(s.c.Facets.PriceBuckets)
+	if err := priceBounds.Validate(); err != nil {
+		s.logger.Error("invalid facets config", "err", err)
+		os.Exit(1)
+	}
+	duplicateCheck := product.DuplicateCheck{
+		Mode: s.c.Duplicates.Mode,
+		Criteria: domain.DuplicateCriteria{
+			NameSimilarity: s.c.Duplicates.NameSimilarity,
+			PriceTolerance: s.c.Duplicates.PriceTolerance,
+		},
+	}
+	if err := duplicateCheck.Validate(); err != nil {
+		s.logger.Error("invalid duplicates config", "err", err)
+		os.Exit(1)
+	}
+	registerUseCase := usecase.Observe("user.register", user.NewRegisterUseCase(userRepository, s.logger).Execute, observers...)
+	loginUseCase := usecase.Observe("user.login", user.NewLoginUseCase(userRepository, s.c.Auth.JwtAuth, s.c.Auth.JwtExpiresIn, s.logger).Execute, loginObservers...)
+	fetchPagedProductsUseCase := usecase.Observe("product.fetch_paged", product.NewFetchPagedProductsUseCase(productRepository, viewRepository, priceBounds, s.logger).Execute, observers...)
+	fetchByIdUseCase := usecase.Observe("product.fetch_by_id", product.NewFetchByIdUseCase(productRepository, s.logger).Execute, observers...)
+	fetchByIdsUseCase := usecase.Observe("product.fetch_by_ids", product.NewFetchByIdsUseCase(productRepository, s.logger).Execute, observers...)
+	searchUseCase := usecase.Observe("product.search", product.NewSearchUseCase(productRepository, priceBounds, s.logger).Execute, observers...)
+	suggestUseCase := usecase.Observe("product.suggest", product.NewSuggestUseCase(productRepository, s.logger).Execute, observers...)
+	createUseCase := usecase.Observe("product.create", product.NewCreateUseCase(productRepository, bus, duplicateCheck, s.logger).Execute, observers...)
+	fetchDuplicatesUseCase := usecase.Observe("product.fetch_duplicates", product.NewFetchDuplicatesUseCase(productRepository, duplicateCheck.Criteria, s.c.Duplicates.ReportTimeout, s.logger).Execute, observers...)
+	updateUseCase := usecase.Observe("product.update", product.NewUpdateUseCase(productRepository, txManager, bus, s.logger).Execute, observers...)
+	deleteUseCase := usecase.Observe("product.delete", product.NewDeleteUseCase(productRepository, bus, s.logger).Execute, observers...)
+	fetchAllWebhooksUseCase := usecase.Observe("webhook.fetch_all", webhookUseCase.NewFetchAllUseCase(webhookRepository, s.logger).Execute, observers...)
+	fetchWebhookByIdUseCase := usecase.Observe("webhook.fetch_by_id", webhookUseCase.NewFetchByIdUseCase(webhookRepository, s.logger).Execute, observers...)
+	createWebhookUseCase := usecase.Observe("webhook.create", webhookUseCase.NewCreateUseCase(webhookRepository, s.logger).Execute, observers...)
+	updateWebhookUseCase := usecase.Observe("webhook.update", webhookUseCase.NewUpdateUseCase(webhookRepository, s.logger).Execute, observers...)
+	deleteWebhookUseCase := usecase.Observe("webhook.delete", webhookUseCase.NewDeleteUseCase(webhookRepository, s.logger).Execute, observers...)
+	fetchDeliveriesUseCase := usecase.Observe("webhook.fetch_deliveries", webhookUseCase.NewFetchDeliveriesUseCase(webhookRepository, webhookDeliveryRepository, s.logger).Execute, observers...)
+	fetchAllViewsUseCase := usecase.Observe("view.fetch_all", viewUseCase.NewFetchAllUseCase(viewRepository, s.logger).Execute, observers...)
+	fetchViewByIdUseCase := usecase.Observe("view.fetch_by_id", viewUseCase.NewFetchByIdUseCase(viewRepository, s.logger).Execute, observers...)
+	createViewUseCase := usecase.Observe("view.create", viewUseCase.NewCreateUseCase(viewRepository, s.logger).Execute, observers...)
+	updateViewUseCase := usecase.Observe("view.update", viewUseCase.NewUpdateUseCase(viewRepository, s.logger).Execute, observers...)
+	deleteViewUseCase := usecase.Observe("view.delete", viewUseCase.NewDeleteUseCase(viewRepository, s.logger).Execute, observers...)
+	redeliverUseCase := usecase.Observe("webhook.redeliver", webhookUseCase.NewRedeliverUseCase(webhookRepository, webhookDeliveryRepository, dispatcher, s.logger).Execute, observers...)
+
+	// health
+	checker := health.NewChecker(s.c.Server.HealthTimeout)
+	if s.db != nil {
+		checker.Register("database", s.db.Ping)
+		migrationChecker, err := database.NewMigrationChecker(s.db, migrations.FS)
+		if err != nil {
+			s.logger.Error("could not read embedded migrations", "err", err)
+			os.Exit(1)
+		}
+		checker.Register("migrations", migrationChecker.Check)
+	}
+	if s.sqlite != nil {
+		checker.Register("database", s.sqlite.PingContext)
+		sqliteMigrations, err := fs.Sub(migrations.SQLite, "sqlite")
+		if err != nil {
+			s.logger.Error("could not read embedded migrations", "err", err)
+			os.Exit(1)
+		}
+		migrationChecker, err := sqlite.NewMigrationChecker(s.sqlite, sqliteMigrations)
+		if err != nil {
+			s.logger.Error("could not read embedded migrations", "err", err)
+			os.Exit(1)
+		}
+		checker.Register("migrations", migrationChecker.Check)
+	}
+	checker.Register("webhook_dispatcher", dispatcher.Check)
+	if listener != nil {
+		checker.Register("event_listener", listener.Check)
+	}
 
 	// handlers
-	userHandler := handler.NewUserHandler(registerUseCase, loginUseCase)
-	productHandler := handler.NewProductHandler(fetchPagedProductsUseCase, fetchByIdUseCase, createUseCase, updateUseCase, deleteUseCase)
+	userHandler := handler.NewUserHandler(registerUseCase, loginUseCase, s.logger)
+	productHandler := handler.NewProductHandler(fetchPagedProductsUseCase, fetchByIdUseCase, fetchByIdsUseCase, searchUseCase, suggestUseCase, fetchDuplicatesUseCase, createUseCase, updateUseCase, deleteUseCase, s.logger)
+	productEventHandler := handler.NewProductEventHandler(stream, s.c.Events.Heartbeat, s.logger)
+	webhookHandler := handler.NewWebhookHandler(
+		fetchAllWebhooksUseCase,
+		fetchWebhookByIdUseCase,
+		createWebhookUseCase,
+		updateWebhookUseCase,
+		deleteWebhookUseCase,
+		fetchDeliveriesUseCase,
+		redeliverUseCase,
+		s.logger,
+	)
+
+	viewHandler := handler.NewViewHandler(
+		fetchAllViewsUseCase,
+		fetchViewByIdUseCase,
+		createViewUseCase,
+		updateViewUseCase,
+		deleteViewUseCase,
+		s.logger,
+	)
 
 	s.container = &Container{
-		UserHandler:    userHandler,
-		ProductHandler: productHandler,
+		UserHandler:         userHandler,
+		ProductHandler:      productHandler,
+		ProductEventHandler: productEventHandler,
+		WebhookHandler:      webhookHandler,
+		ViewHandler:         viewHandler,
+		HealthHandler:       handler.NewHealthHandler(checker),
+		Health:              checker,
+		Dispatcher:          dispatcher,
+		Stream:              stream,
+		Listener:            listener,
+	}
+}
+
+func (s *Server) seed(users domain.UserRepository, products domain.ProductRepository) {
+	f, err := os.Open(s.opts.Seed)
+	if err != nil {
+		s.logger.Error("could not open seed", "err", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	if err := memory.LoadSeed(context.Background(), f, users, products); err != nil {
+		s.logger.Error("could not load seed", "path", s.opts.Seed, "err", err)
+		os.Exit(1)
 	}
+	s.logger.Info("loaded seed", "path", s.opts.Seed)
 }
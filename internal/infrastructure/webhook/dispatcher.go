@@ -0,0 +1,294 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/config"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/infrastructure/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	HeaderSignature = "X-Signature"
+	HeaderWebhook   = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+var errDispatcherClosed = errors.New("dispatcher is closed")
+
+// Dispatcher fans product events out to the subscribed webhooks and delivers
+// them in the background, retrying failed attempts with jittered exponential
+// backoff. Every attempt is claimed in the delivery repository first, so
+// deliveries resumed by several instances are still sent once per attempt.
+type Dispatcher struct {
+	webhookRepository  domain.WebhookRepository
+	deliveryRepository domain.WebhookDeliveryRepository
+	logger             *slog.Logger
+	client             *http.Client
+	maxAttempts        int
+	backoff            time.Duration
+	backoffMax         time.Duration
+	// hold is how long a claimed attempt keeps other instances away, in
+	// case this one dies before recording its outcome
+	hold time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(
+	webhookRepository domain.WebhookRepository,
+	deliveryRepository domain.WebhookDeliveryRepository,
+	c *config.ConfWebhook,
+	logger *slog.Logger,
+) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Dispatcher{
+		webhookRepository:  webhookRepository,
+		deliveryRepository: deliveryRepository,
+		logger:             logger,
+		client:             newClient(c),
+		maxAttempts:        max(c.MaxAttempts, 1),
+		backoff:            c.Backoff,
+		backoffMax:         c.BackoffMax,
+		hold:               c.Timeout + time.Minute,
+		ctx:                ctx,
+		cancel:             cancel,
+	}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, e *domain.Event) {
+	// keep the request scoped values for logging but not its cancellation
+	ctx = context.WithoutCancel(ctx)
+	d.goBackground(func() {
+		webhooks, err := d.webhookRepository.FetchByEvent(d.ctx, e.Type)
+		if err != nil {
+			d.logger.ErrorContext(ctx, "could not fetch webhook subscriptions", "event_type", e.Type, "err", err)
+			return
+		}
+		if len(webhooks) == 0 {
+			return
+		}
+
+		body, err := json.Marshal(event.NewPayload(e))
+		if err != nil {
+			d.logger.ErrorContext(ctx, "could not encode event", "event_id", e.Id, "err", err)
+			return
+		}
+
+		for _, webhook := range webhooks {
+			delivery := domain.NewWebhookDelivery(webhook.Id, e.Id, e.Type, body)
+			if err := d.deliveryRepository.Create(d.ctx, delivery); err != nil {
+				d.logger.ErrorContext(ctx, "could not persist webhook delivery", "webhook_id", webhook.Id, "err", err)
+				continue
+			}
+			d.Dispatch(ctx, webhook, delivery)
+		}
+	})
+}
+
+func (d *Dispatcher) Dispatch(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) {
+	ctx = context.WithoutCancel(ctx)
+	d.goBackground(func() {
+		d.deliver(ctx, webhook, delivery)
+	})
+}
+
+// Resume picks up the deliveries left pending by a previous run, each at
+// the time its next attempt was due.
+func (d *Dispatcher) Resume(ctx context.Context) error {
+	deliveries, err := d.deliveryRepository.FetchPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	webhooks := make(map[uuid.UUID]*domain.Webhook)
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookId]
+		if !ok {
+			webhook, err = d.webhookRepository.FetchById(ctx, delivery.WebhookId)
+			if errors.Is(err, domain.ErrWebhookNotFound) {
+				d.logger.WarnContext(ctx, "skipping pending delivery of a deleted webhook", "webhook_id", delivery.WebhookId, "delivery_id", delivery.Id)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			webhooks[delivery.WebhookId] = webhook
+		}
+		d.Dispatch(ctx, webhook, delivery)
+	}
+	if len(deliveries) > 0 {
+		d.logger.InfoContext(ctx, "resumed pending webhook deliveries", "count", len(deliveries))
+	}
+
+	return nil
+}
+
+// Close stops retrying and waits for in-flight deliveries to return.
+// Deliveries interrupted between attempts stay pending until Resume.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Check reports the dispatcher as unhealthy once it stopped taking
+// deliveries.
+func (d *Dispatcher) Check(context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return errDispatcherClosed
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) goBackground(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		fn()
+	}()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) {
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(time.Until(delivery.NextAttemptAt)):
+		}
+
+		err := d.deliveryRepository.Claim(d.ctx, delivery, time.Now().Add(d.hold))
+		if errors.Is(err, domain.ErrWebhookDeliveryClaimed) {
+			d.logger.DebugContext(ctx, "webhook delivery attempt taken by another instance", "webhook_id", webhook.Id, "delivery_id", delivery.Id)
+			return
+		}
+		if err != nil {
+			d.logger.ErrorContext(ctx, "could not claim webhook delivery, leaving it pending", "webhook_id", webhook.Id, "delivery_id", delivery.Id, "err", err)
+			return
+		}
+
+		code, err := d.send(ctx, webhook, delivery)
+
+		now := time.Now()
+		delivery.ResponseCode = code
+		delivery.UpdatedAt = now
+		switch {
+		case err == nil:
+			delivery.Status = domain.DeliverySucceeded
+			delivery.Error = ""
+			delivery.DeliveredAt = &now
+		case delivery.Attempts >= d.maxAttempts:
+			delivery.Status = domain.DeliveryFailed
+			delivery.Error = err.Error()
+		default:
+			delivery.Error = err.Error()
+			delivery.NextAttemptAt = now.Add(d.retryDelay(delivery.Attempts))
+		}
+
+		logger := d.logger.With("webhook_id", webhook.Id, "delivery_id", delivery.Id, "attempt", delivery.Attempts, "response_code", code)
+		switch delivery.Status {
+		case domain.DeliverySucceeded:
+			logger.InfoContext(ctx, "webhook delivered")
+		case domain.DeliveryFailed:
+			logger.ErrorContext(ctx, "webhook delivery failed, giving up", "err", err)
+		default:
+			logger.WarnContext(ctx, "webhook delivery failed, retrying", "err", err)
+		}
+
+		if err := d.deliveryRepository.Update(d.ctx, delivery); err != nil {
+			logger.ErrorContext(ctx, "could not update webhook delivery", "err", err)
+		}
+		if delivery.Status != domain.DeliveryPending {
+			return
+		}
+	}
+}
+
+// retryDelay doubles the backoff with every attempt up to backoffMax and
+// randomizes the second half of it, so receivers coming back up are not hit
+// by every retry at once.
+func (d *Dispatcher) retryDelay(attempts int) time.Duration {
+	backoff := d.backoffMax
+	// compare before shifting, as the shift overflows long past the cap
+	if shift := max(attempts-1, 0); shift < 63 && d.backoff <= d.backoffMax>>shift {
+		backoff = d.backoff << shift
+	}
+
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// send makes one attempt. It is cancelled with the dispatcher, while ctx
+// only carries the trace context of the change that caused the delivery.
+func (d *Dispatcher) send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, webhook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, time.Now().Unix(), delivery.Payload))
+	req.Header.Set(HeaderWebhook, webhook.Id.String())
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, delivery.Id.String())
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// Sign builds the X-Signature header value: "t=<unix>,v1=<hex>" where the
+// HMAC-SHA256 is computed over "<unix>.<body>" with the webhook secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,372 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/config"
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/infrastructure/event"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type webhookRepositoryStub struct {
+	domain.WebhookRepository
+	webhooks []*domain.Webhook
+}
+
+func (r *webhookRepositoryStub) FetchByEvent(_ context.Context, _ domain.EventType) ([]*domain.Webhook, error) {
+	return r.webhooks, nil
+}
+
+func (r *webhookRepositoryStub) FetchById(_ context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	for _, w := range r.webhooks {
+		if w.Id == id {
+			return w, nil
+		}
+	}
+	return nil, domain.ErrWebhookNotFound
+}
+
+type deliveryRepositoryStub struct {
+	domain.WebhookDeliveryRepository
+	mu         sync.Mutex
+	deliveries map[uuid.UUID]domain.WebhookDelivery
+}
+
+func (r *deliveryRepositoryStub) Create(_ context.Context, d *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[d.Id] = *d
+	return nil
+}
+
+func (r *deliveryRepositoryStub) Update(_ context.Context, d *domain.WebhookDelivery) error {
+	return r.Create(context.Background(), d)
+}
+
+func (r *deliveryRepositoryStub) FetchPending(_ context.Context) ([]*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pending []*domain.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == domain.DeliveryPending {
+			pending = append(pending, &d)
+		}
+	}
+	return pending, nil
+}
+
+func (r *deliveryRepositoryStub) Claim(_ context.Context, d *domain.WebhookDelivery, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.deliveries[d.Id]
+	if stored.Attempts != d.Attempts {
+		return domain.ErrWebhookDeliveryClaimed
+	}
+	d.Attempts++
+	d.NextAttemptAt = until
+	r.deliveries[d.Id] = *d
+	return nil
+}
+
+func (r *deliveryRepositoryStub) only() domain.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.deliveries {
+		return d
+	}
+	return domain.WebhookDelivery{}
+}
+
+func newDispatcher(url string, maxAttempts int) (*Dispatcher, *domain.Webhook, *deliveryRepositoryStub) {
+	return newDispatcherWithConfig(url, &config.ConfWebhook{MaxAttempts: maxAttempts, Backoff: time.Millisecond, BackoffMax: time.Second, Timeout: time.Second, AllowPrivateTargets: true})
+}
+
+func newDispatcherWithConfig(url string, c *config.ConfWebhook) (*Dispatcher, *domain.Webhook, *deliveryRepositoryStub) {
+	w, _ := domain.NewWebhook(uuid.New(), url, []domain.EventType{domain.EventProductCreated}, "s3cr3t")
+	deliveries := &deliveryRepositoryStub{deliveries: map[uuid.UUID]domain.WebhookDelivery{}}
+	d := NewDispatcher(
+		&webhookRepositoryStub{webhooks: []*domain.Webhook{w}},
+		deliveries,
+		c,
+		slog.New(slog.DiscardHandler),
+	)
+
+	return d, w, deliveries
+}
+
+func TestDispatcherSignsDelivery(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer receiver.Close()
+
+	d, w, deliveries := newDispatcher(receiver.URL, 3)
+	p, _ := domain.NewProduct("Product", 10)
+	d.Publish(context.Background(), domain.NewProductEvent(domain.EventProductCreated, p.Id, p))
+
+	assert.Eventually(t, func() bool {
+		return deliveries.only().Status == domain.DeliverySucceeded
+	}, time.Second, 5*time.Millisecond)
+	d.Close()
+
+	r := <-received
+	body := <-bodies
+
+	signature := r.Header.Get(HeaderSignature)
+	ts, err := strconv.ParseInt(strings.TrimPrefix(strings.Split(signature, ",")[0], "t="), 10, 64)
+	assert.Nil(t, err)
+	assert.Equal(t, Sign("s3cr3t", ts, body), signature)
+	assert.Equal(t, w.Id.String(), r.Header.Get(HeaderWebhook))
+	assert.Equal(t, string(domain.EventProductCreated), r.Header.Get(HeaderEvent))
+
+	var got event.Payload
+	assert.Nil(t, json.Unmarshal(body, &got))
+	assert.Equal(t, p.Id, got.Data.Id)
+	assert.Equal(t, "Product", got.Data.Name)
+
+	delivery := deliveries.only()
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.ResponseCode)
+	assert.NotNil(t, delivery.DeliveredAt)
+}
+
+func TestDispatcherForwardsTraceContext(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+
+	received := make(chan *http.Request, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r
+	}))
+	defer receiver.Close()
+
+	d, _, deliveries := newDispatcher(receiver.URL, 3)
+	defer d.Close()
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+	p, _ := domain.NewProduct("Product", 10)
+	d.Publish(ctx, domain.NewProductEvent(domain.EventProductCreated, p.Id, p))
+
+	assert.Eventually(t, func() bool {
+		return deliveries.only().Status == domain.DeliverySucceeded
+	}, time.Second, 5*time.Millisecond)
+
+	r := <-received
+	assert.Equal(t, "00-01000000000000000000000000000000-0200000000000000-01", r.Header.Get("traceparent"))
+}
+
+func TestDispatcherRetriesUntilSuccess(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	d, w, deliveries := newDispatcher(receiver.URL, 5)
+	delivery := domain.NewWebhookDelivery(w.Id, uuid.New(), domain.EventProductCreated, []byte("{}"))
+	assert.NoError(t, deliveries.Create(context.Background(), delivery))
+	d.Dispatch(context.Background(), w, delivery)
+
+	assert.Eventually(t, func() bool {
+		return deliveries.only().Status == domain.DeliverySucceeded
+	}, time.Second, 5*time.Millisecond)
+	d.Close()
+
+	got := deliveries.only()
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, domain.DeliverySucceeded, got.Status)
+	assert.Equal(t, 3, got.Attempts)
+}
+
+func TestDispatcherGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	d, w, deliveries := newDispatcher(receiver.URL, 2)
+	delivery := domain.NewWebhookDelivery(w.Id, uuid.New(), domain.EventProductCreated, []byte("{}"))
+	assert.NoError(t, deliveries.Create(context.Background(), delivery))
+	d.Dispatch(context.Background(), w, delivery)
+
+	assert.Eventually(t, func() bool {
+		return deliveries.only().Status == domain.DeliveryFailed
+	}, time.Second, 5*time.Millisecond)
+	d.Close()
+
+	got := deliveries.only()
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, 2, got.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, got.ResponseCode)
+	assert.NotEmpty(t, got.Error)
+}
+
+func TestDispatcherResumesPendingDeliveries(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	d, w, deliveries := newDispatcher(receiver.URL, 3)
+	// left by a previous run after a failed attempt, with a retry due
+	delivery := domain.NewWebhookDelivery(w.Id, uuid.New(), domain.EventProductCreated, []byte("{}"))
+	delivery.Attempts = 1
+	delivery.NextAttemptAt = time.Now().Add(20 * time.Millisecond)
+	assert.NoError(t, deliveries.Create(context.Background(), delivery))
+
+	assert.NoError(t, d.Resume(context.Background()))
+
+	assert.Eventually(t, func() bool {
+		return deliveries.only().Status == domain.DeliverySucceeded
+	}, time.Second, 5*time.Millisecond)
+	d.Close()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, 2, deliveries.only().Attempts)
+}
+
+func TestDispatcherLeavesAttemptClaimedElsewhere(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	d, w, deliveries := newDispatcher(receiver.URL, 3)
+	delivery := domain.NewWebhookDelivery(w.Id, uuid.New(), domain.EventProductCreated, []byte("{}"))
+	assert.NoError(t, deliveries.Create(context.Background(), delivery))
+	// another instance resumed the same delivery first
+	stale := *delivery
+	assert.NoError(t, deliveries.Claim(context.Background(), delivery, time.Now().Add(time.Minute)))
+
+	d.deliver(context.Background(), w, &stale)
+	d.Close()
+
+	assert.Zero(t, calls.Load())
+	assert.Equal(t, domain.DeliveryPending, deliveries.only().Status)
+}
+
+func TestRetryDelayIsJittered(t *testing.T) {
+	d, _, _ := newDispatcher("https://example.com/hook", 5)
+	d.backoff = time.Second
+	d.backoffMax = time.Hour
+
+	seen := make(map[time.Duration]bool)
+	for range 50 {
+		delay := d.retryDelay(3)
+		assert.GreaterOrEqual(t, delay, 2*time.Second)
+		assert.LessOrEqual(t, delay, 4*time.Second)
+		seen[delay] = true
+	}
+	assert.Greater(t, len(seen), 1)
+}
+
+func TestRetryDelayIsCapped(t *testing.T) {
+	d, _, _ := newDispatcher("https://example.com/hook", 5)
+	d.backoff = time.Second
+	d.backoffMax = time.Hour
+
+	tests := []struct {
+		attempts int
+		min, max time.Duration
+	}{
+		{attempts: 1, min: 500 * time.Millisecond, max: time.Second},
+		{attempts: 12, min: 1024 * time.Second, max: 2048 * time.Second},
+		{attempts: 13, min: 30 * time.Minute, max: time.Hour},
+		{attempts: 21, min: 30 * time.Minute, max: time.Hour},
+		{attempts: 36, min: 30 * time.Minute, max: time.Hour},
+		{attempts: 64, min: 30 * time.Minute, max: time.Hour},
+		{attempts: 1000, min: 30 * time.Minute, max: time.Hour},
+	}
+	for _, tt := range tests {
+		delay := d.retryDelay(tt.attempts)
+		assert.GreaterOrEqual(t, delay, tt.min, tt.attempts)
+		assert.LessOrEqual(t, delay, tt.max, tt.attempts)
+	}
+}
+
+func TestDispatcherRefusesPrivateTargets(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	d, w, deliveries := newDispatcherWithConfig(receiver.URL, &config.ConfWebhook{MaxAttempts: 1, Timeout: time.Second})
+	delivery := domain.NewWebhookDelivery(w.Id, uuid.New(), domain.EventProductCreated, []byte("{}"))
+	assert.NoError(t, deliveries.Create(context.Background(), delivery))
+	d.Dispatch(context.Background(), w, delivery)
+
+	assert.Eventually(t, func() bool {
+		return deliveries.only().Status == domain.DeliveryFailed
+	}, time.Second, 5*time.Millisecond)
+	d.Close()
+
+	assert.Zero(t, calls.Load())
+	assert.Contains(t, deliveries.only().Error, errTargetNotAllowed.Error())
+}
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected bool
+	}{
+		{"93.184.215.14", true},
+		{"2606:2800:21f:cb07:6820:80da:af6b:8b2c", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, publicAddr(netip.MustParseAddr(tt.addr)), tt.addr)
+	}
+}
+
+func TestDispatcherCheckFailsOnceClosed(t *testing.T) {
+	d := NewDispatcher(nil, nil, &config.ConfWebhook{MaxAttempts: 1, Timeout: time.Second}, slog.New(slog.DiscardHandler))
+	assert.NoError(t, d.Check(context.Background()))
+
+	d.Close()
+
+	assert.Error(t, d.Check(context.Background()))
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+
+	"github.com/rosset7i/product_crud/config"
+)
+
+var errTargetNotAllowed = errors.New("webhook target address is not allowed")
+
+// nonPublicPrefixes are the ranges not reachable from the internet that
+// netip does not already classify as private, loopback or link-local.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// newClient builds the client deliveries are sent with. Unless private
+// targets are allowed, the address is checked once resolved, right before
+// dialing, so neither DNS tricks nor redirects reach internal services.
+func newClient(c *config.ConfWebhook) *http.Client {
+	dialer := &net.Dialer{Timeout: c.Timeout}
+	if !c.AllowPrivateTargets {
+		dialer.Control = dialPublicOnly
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would dial the target itself, out of reach of the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: c.Timeout, Transport: transport}
+}
+
+func dialPublicOnly(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errTargetNotAllowed, addrPort.Addr())
+	}
+
+	return nil
+}
+
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -2,27 +2,85 @@ package product
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/rosset7i/product_crud/internal/domain"
 )
 
+// Duplicate check modes: off skips it, warn lists likely duplicates in the
+// response and reject refuses to create the product unless AllowDuplicate
+// is set.
+const (
+	DuplicatesOff    = "off"
+	DuplicatesWarn   = "warn"
+	DuplicatesReject = "reject"
+)
+
+const maxPossibleDuplicates = 5
+
+type DuplicateCheck struct {
+	Mode     string
+	Criteria domain.DuplicateCriteria
+}
+
+var errUnknownDuplicatesMode = errors.New("duplicates mode must be off, warn or reject")
+
+func (c DuplicateCheck) Validate() error {
+	switch c.Mode {
+	case DuplicatesOff:
+		return nil
+	case DuplicatesWarn, DuplicatesReject:
+		return c.Criteria.Validate()
+	}
+
+	return errUnknownDuplicatesMode
+}
+
+// DuplicateError is returned when the product looks like one that already
+// exists and the check rejects duplicates.
+type DuplicateError struct {
+	Duplicates []ProductResponse
+}
+
+func (e *DuplicateError) Error() string {
+	return "product looks like a duplicate, set allowDuplicate to create it anyway"
+}
+
 type CreateRequest struct {
 	Name  string  `json:"name"`
 	Price float64 `json:"price"`
+	// AllowDuplicate skips the duplicate check. It comes from the query
+	// string rather than the body.
+	AllowDuplicate bool `json:"-"`
 }
 
 type CreateResponse struct {
 	Id uuid.UUID `json:"id"`
+	// PossibleDuplicates lists existing products the new one looks like.
+	PossibleDuplicates []ProductResponse `json:"possible_duplicates,omitempty"`
+}
+
+// DuplicateResponse is the conflict body listing the likely duplicates.
+type DuplicateResponse struct {
+	Message    string            `json:"message"`
+	Duplicates []ProductResponse `json:"duplicates"`
 }
 
 type CreateUseCase struct {
 	productRepository domain.ProductRepository
+	eventPublisher    domain.EventPublisher
+	duplicates        DuplicateCheck
+	logger            *slog.Logger
 }
 
-func NewCreateUseCase(productRepository domain.ProductRepository) *CreateUseCase {
+func NewCreateUseCase(productRepository domain.ProductRepository, eventPublisher domain.EventPublisher, duplicates DuplicateCheck, logger *slog.Logger) *CreateUseCase {
 	return &CreateUseCase{
 		productRepository: productRepository,
+		eventPublisher:    eventPublisher,
+		duplicates:        duplicates,
+		logger:            logger,
 	}
 }
 
@@ -32,12 +90,31 @@ func (uc *CreateUseCase) Execute(ctx context.Context, r CreateRequest) (CreateRe
 		return CreateResponse{}, err
 	}
 
+	var possibleDuplicates []ProductResponse
+	if uc.duplicates.Mode != DuplicatesOff && !r.AllowDuplicate {
+		similar, err := uc.productRepository.FetchSimilar(ctx, p, uc.duplicates.Criteria, maxPossibleDuplicates)
+		if err != nil {
+			return CreateResponse{}, err
+		}
+		for _, s := range similar {
+			possibleDuplicates = append(possibleDuplicates, mapProduct(s))
+		}
+		if len(possibleDuplicates) > 0 && uc.duplicates.Mode == DuplicatesReject {
+			uc.logger.InfoContext(ctx, "product rejected as a duplicate", "name", p.Name, "duplicates", len(possibleDuplicates))
+			return CreateResponse{}, &DuplicateError{Duplicates: possibleDuplicates}
+		}
+	}
+
 	err = uc.productRepository.Create(ctx, p)
 	if err != nil {
 		return CreateResponse{}, err
 	}
 
+	uc.logger.InfoContext(ctx, "product created", "product_id", p.Id, "possible_duplicates", len(possibleDuplicates))
+	uc.eventPublisher.Publish(ctx, domain.NewProductEvent(domain.EventProductCreated, p.Id, p))
+
 	return CreateResponse{
-		Id: p.Id,
+		Id:                 p.Id,
+		PossibleDuplicates: possibleDuplicates,
 	}, nil
 }
@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/rosset7i/product_crud/internal/domain"
@@ -17,11 +18,15 @@ type DeleteResponse struct {
 
 type DeleteUseCase struct {
 	productRepository domain.ProductRepository
+	eventPublisher    domain.EventPublisher
+	logger            *slog.Logger
 }
 
-func NewDeleteUseCase(productRepository domain.ProductRepository) *DeleteUseCase {
+func NewDeleteUseCase(productRepository domain.ProductRepository, eventPublisher domain.EventPublisher, logger *slog.Logger) *DeleteUseCase {
 	return &DeleteUseCase{
 		productRepository: productRepository,
+		eventPublisher:    eventPublisher,
+		logger:            logger,
 	}
 }
 
@@ -31,5 +36,8 @@ func (uc *DeleteUseCase) Execute(ctx context.Context, r DeleteRequest) (DeleteRe
 		return DeleteResponse{}, err
 	}
 
+	uc.logger.InfoContext(ctx, "product deleted", "product_id", r.Id)
+	uc.eventPublisher.Publish(ctx, domain.NewProductEvent(domain.EventProductDeleted, r.Id, nil))
+
 	return DeleteResponse(r), nil
 }
@@ -0,0 +1,70 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
+)
+
+// FacetPrice counts products per configured price bucket. It is the only
+// facet for now, products having no category, status or tags.
+const FacetPrice = "price"
+
+// ErrUnsupportedFacet is returned when a listing asks for a facet other
+// than the supported ones.
+var ErrUnsupportedFacet = errors.New("unsupported facet")
+
+type FacetsResponse struct {
+	Price []PriceBucketResponse `json:"price,omitempty"`
+}
+
+// PriceBucketResponse counts the products priced from From up to, but
+// excluding, To. The last bucket has no upper end.
+type PriceBucketResponse struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to,omitempty"`
+	Count int      `json:"count"`
+}
+
+type facetCounter struct {
+	productRepository domain.ProductRepository
+	priceBounds       domain.PriceBounds
+}
+
+func validateFacets(facets []string) error {
+	for _, facet := range facets {
+		if facet != FacetPrice {
+			return fmt.Errorf("%w %q, supported facets are: %s", ErrUnsupportedFacet, facet, FacetPrice)
+		}
+	}
+
+	return nil
+}
+
+// count computes the requested facets over the same products the listing
+// draws from: the matches of query and where, either of which can be empty.
+func (f facetCounter) count(ctx context.Context, query string, where filter.Expr, facets []string) (*FacetsResponse, error) {
+	if len(facets) == 0 {
+		return nil, nil
+	}
+
+	counts, err := f.productRepository.CountByPrice(ctx, query, where, f.priceBounds)
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]PriceBucketResponse, len(counts))
+	for i, count := range counts {
+		buckets[i].Count = count
+		if i > 0 {
+			buckets[i].From = f.priceBounds[i-1]
+		}
+		if i < len(f.priceBounds) {
+			buckets[i].To = &f.priceBounds[i]
+		}
+	}
+
+	return &FacetsResponse{Price: buckets}, nil
+}
@@ -2,45 +2,46 @@ package product
 
 import (
 	"context"
-	"time"
+	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/rosset7i/product_crud/internal/domain"
 )
 
 type FetchByIdRequest struct {
-	Id uuid.UUID `json:"id"`
+	Id     uuid.UUID `json:"id"`
+	Fields []string  `json:"fields"`
+	Expand []string  `json:"expand"`
 }
 
 type FetchByIdResponse struct {
-	Id        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ProductResponse
 }
 
 type FetchByIdUseCase struct {
 	productRepository domain.ProductRepository
+	logger            *slog.Logger
 }
 
-func NewFetchByIdUseCase(productRepository domain.ProductRepository) *FetchByIdUseCase {
+func NewFetchByIdUseCase(productRepository domain.ProductRepository, logger *slog.Logger) *FetchByIdUseCase {
 	return &FetchByIdUseCase{
 		productRepository: productRepository,
+		logger:            logger,
 	}
 }
 
 func (uc *FetchByIdUseCase) Execute(ctx context.Context, r FetchByIdRequest) (FetchByIdResponse, error) {
+	if err := validateFields(r.Fields); err != nil {
+		return FetchByIdResponse{}, err
+	}
+	if err := validateExpansions(r.Expand); err != nil {
+		return FetchByIdResponse{}, err
+	}
 	p, err := uc.productRepository.FetchById(ctx, r.Id)
 	if err != nil {
+		uc.logger.DebugContext(ctx, "product not fetched", "product_id", r.Id, "err", err)
 		return FetchByIdResponse{}, err
 	}
 
-	return FetchByIdResponse{
-		Id:        p.Id,
-		Name:      p.Name,
-		Price:     p.Price,
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
-	}, nil
+	return FetchByIdResponse{ProductResponse: mapProduct(p).only(r.Fields)}, nil
 }
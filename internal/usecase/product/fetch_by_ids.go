@@ -0,0 +1,94 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// maxBatchSize bounds how many ids one batch fetch takes, repeats included.
+const maxBatchSize = 100
+
+type FetchByIdsRequest struct {
+	Ids    []uuid.UUID `json:"ids"`
+	Fields []string    `json:"fields"`
+	Expand []string    `json:"expand"`
+}
+
+type FetchByIdsResponse struct {
+	// Products follow the order of the requested ids, each once.
+	Products []ProductResponse `json:"products"`
+	// Missing are the requested ids no product has, in the same order.
+	Missing []uuid.UUID `json:"missing"`
+}
+
+type FetchByIdsUseCase struct {
+	productRepository domain.ProductRepository
+	logger            *slog.Logger
+}
+
+func NewFetchByIdsUseCase(productRepository domain.ProductRepository, logger *slog.Logger) *FetchByIdsUseCase {
+	return &FetchByIdsUseCase{
+		productRepository: productRepository,
+		logger:            logger,
+	}
+}
+
+var (
+	errIdsAreRequired = errors.New("ids are required")
+	errTooManyIds     = fmt.Errorf("at most %d ids can be fetched at once", maxBatchSize)
+)
+
+func (uc *FetchByIdsUseCase) Execute(ctx context.Context, r FetchByIdsRequest) (FetchByIdsResponse, error) {
+	if err := validateFields(r.Fields); err != nil {
+		return FetchByIdsResponse{}, err
+	}
+	if err := validateExpansions(r.Expand); err != nil {
+		return FetchByIdsResponse{}, err
+	}
+
+	// checked before deduplicating, so repeats cannot make the work unbounded
+	if len(r.Ids) > maxBatchSize {
+		return FetchByIdsResponse{}, errTooManyIds
+	}
+
+	ids := make([]uuid.UUID, 0, len(r.Ids))
+	seen := make(map[uuid.UUID]bool, len(r.Ids))
+	for _, id := range r.Ids {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return FetchByIdsResponse{}, errIdsAreRequired
+	}
+
+	products, err := uc.productRepository.FetchByIds(ctx, ids)
+	if err != nil {
+		return FetchByIdsResponse{}, err
+	}
+	byId := make(map[uuid.UUID]*domain.Product, len(products))
+	for _, p := range products {
+		byId[p.Id] = p
+	}
+
+	response := FetchByIdsResponse{
+		Products: make([]ProductResponse, 0, len(products)),
+		Missing:  make([]uuid.UUID, 0),
+	}
+	for _, id := range ids {
+		if p, ok := byId[id]; ok {
+			response.Products = append(response.Products, mapProduct(p).only(r.Fields))
+		} else {
+			response.Missing = append(response.Missing, id)
+		}
+	}
+	uc.logger.DebugContext(ctx, "products fetched by ids", "requested", len(ids), "missing", len(response.Missing))
+
+	return response, nil
+}
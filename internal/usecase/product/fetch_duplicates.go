@@ -0,0 +1,99 @@
+package product
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// maxDuplicatePairs bounds how much of the catalog a single report
+// compares; cleaning up the first clusters makes room for the next ones.
+const maxDuplicatePairs = 1000
+
+type FetchDuplicatesRequest struct{}
+
+type FetchDuplicatesResponse struct {
+	Clusters []DuplicateClusterResponse `json:"clusters"`
+	// Truncated is set when there were more likely duplicates than the
+	// report covers.
+	Truncated bool `json:"truncated"`
+}
+
+type DuplicateClusterResponse struct {
+	Products []ProductResponse `json:"products"`
+}
+
+type FetchDuplicatesUseCase struct {
+	productRepository domain.ProductRepository
+	criteria          domain.DuplicateCriteria
+	timeout           time.Duration
+	logger            *slog.Logger
+}
+
+func NewFetchDuplicatesUseCase(
+	productRepository domain.ProductRepository,
+	criteria domain.DuplicateCriteria,
+	timeout time.Duration,
+	logger *slog.Logger,
+) *FetchDuplicatesUseCase {
+	return &FetchDuplicatesUseCase{
+		productRepository: productRepository,
+		criteria:          criteria,
+		timeout:           timeout,
+		logger:            logger,
+	}
+}
+
+// Execute groups likely duplicate pairs into clusters: products that are
+// duplicates of the same product end up together even when they do not
+// look alike enough themselves. The lookup is given up after the timeout.
+func (uc *FetchDuplicatesUseCase) Execute(ctx context.Context, _ FetchDuplicatesRequest) (FetchDuplicatesResponse, error) {
+	lookupCtx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+	pairs, err := uc.productRepository.FetchDuplicatePairs(lookupCtx, uc.criteria, maxDuplicatePairs+1)
+	if err != nil {
+		return FetchDuplicatesResponse{}, err
+	}
+	truncated := len(pairs) > maxDuplicatePairs
+	if truncated {
+		pairs = pairs[:maxDuplicatePairs]
+	}
+
+	parent := make(map[uuid.UUID]uuid.UUID)
+	var find func(id uuid.UUID) uuid.UUID
+	find = func(id uuid.UUID) uuid.UUID {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	var order []*domain.Product
+	for _, pair := range pairs {
+		for _, p := range pair {
+			if _, ok := parent[p.Id]; !ok {
+				parent[p.Id] = p.Id
+				order = append(order, p)
+			}
+		}
+		parent[find(pair[1].Id)] = find(pair[0].Id)
+	}
+
+	clusters := make([]DuplicateClusterResponse, 0)
+	index := make(map[uuid.UUID]int)
+	for _, p := range order {
+		root := find(p.Id)
+		i, ok := index[root]
+		if !ok {
+			i = len(clusters)
+			index[root] = i
+			clusters = append(clusters, DuplicateClusterResponse{})
+		}
+		clusters[i].Products = append(clusters[i].Products, mapProduct(p))
+	}
+	uc.logger.DebugContext(ctx, "duplicate products fetched", "pairs", len(pairs), "clusters", len(clusters), "truncated", truncated)
+
+	return FetchDuplicatesResponse{Clusters: clusters, Truncated: truncated}, nil
+}
@@ -2,65 +2,151 @@ package product
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rosset7i/product_crud/internal/domain"
+	"github.com/rosset7i/product_crud/internal/domain/filter"
 )
 
 type FetchPagedProductsRequest struct {
 	PageNumber int    `json:"page_number"`
 	PageSize   int    `json:"page_size"`
 	Sort       string `json:"sort"`
+	// Filter is an expression in the filter language, see package filter.
+	Filter string   `json:"filter"`
+	Facets []string `json:"facets"`
+	// Fields trims the products down to those fields.
+	Fields []string `json:"fields"`
+	Expand []string `json:"expand"`
+	// ViewId runs a saved view the user can see: its filter applies along
+	// with Filter, its sort unless Sort is set and its columns unless Fields
+	// are.
+	ViewId uuid.UUID `json:"view_id"`
+	UserId uuid.UUID `json:"-"`
 }
 
 type FetchPagedProductsResponse struct {
 	Products []ProductResponse `json:"products"`
+	// Facets counts all the filtered products, not just the page.
+	Facets *FacetsResponse `json:"facets,omitempty"`
 }
 
 type ProductResponse struct {
-	Id        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Id        uuid.UUID `json:"id,omitzero"`
+	Name      string    `json:"name,omitzero"`
+	Price     float64   `json:"price,omitzero"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
 }
 
 type FetchPagedProductsUseCase struct {
 	productRepository domain.ProductRepository
+	viewRepository    domain.ViewRepository
+	facets            facetCounter
+	logger            *slog.Logger
 }
 
-func NewFetchPagedProductsUseCase(productRepository domain.ProductRepository) *FetchPagedProductsUseCase {
+func NewFetchPagedProductsUseCase(productRepository domain.ProductRepository, viewRepository domain.ViewRepository, priceBounds domain.PriceBounds, logger *slog.Logger) *FetchPagedProductsUseCase {
 	return &FetchPagedProductsUseCase{
 		productRepository: productRepository,
+		viewRepository:    viewRepository,
+		facets:            facetCounter{productRepository: productRepository, priceBounds: priceBounds},
+		logger:            logger,
 	}
 }
 
 func (uc *FetchPagedProductsUseCase) Execute(ctx context.Context, r FetchPagedProductsRequest) (FetchPagedProductsResponse, error) {
+	if err := validateFacets(r.Facets); err != nil {
+		return FetchPagedProductsResponse{}, err
+	}
+	if err := validateFields(r.Fields); err != nil {
+		return FetchPagedProductsResponse{}, err
+	}
+	if err := validateExpansions(r.Expand); err != nil {
+		return FetchPagedProductsResponse{}, err
+	}
+	filters := []string{r.Filter}
+	sort := r.Sort
+	fields := r.Fields
+	if r.ViewId != uuid.Nil {
+		v, err := uc.viewRepository.FetchById(ctx, r.ViewId)
+		if err != nil {
+			return FetchPagedProductsResponse{}, err
+		}
+		if !v.VisibleTo(r.UserId) {
+			return FetchPagedProductsResponse{}, domain.ErrViewNotFound
+		}
+		filters = append(filters, v.Filter)
+		if sort == "" {
+			sort = v.Sort
+		}
+		if len(fields) == 0 {
+			fields = v.Columns
+		}
+	}
+	where, err := parseFilters(filters)
+	if err != nil {
+		return FetchPagedProductsResponse{}, err
+	}
+
 	products, err := uc.productRepository.FetchPaged(
 		ctx,
 		r.PageNumber,
 		r.PageSize,
-		r.Sort,
+		sort,
+		where,
 	)
 	if err != nil {
 		return FetchPagedProductsResponse{}, err
 	}
+	uc.logger.DebugContext(ctx, "products fetched", "page_number", r.PageNumber, "page_size", r.PageSize, "count", len(products))
 
-	return FetchPagedProductsResponse{Products: mapProducts(products)}, nil
+	facets, err := uc.facets.count(ctx, "", where, r.Facets)
+	if err != nil {
+		return FetchPagedProductsResponse{}, err
+	}
+
+	return FetchPagedProductsResponse{Products: mapProducts(products, fields), Facets: facets}, nil
 }
 
-func mapProducts(products []*domain.Product) []ProductResponse {
+// parseFilters parses the non-empty filters, all of which have to match.
+func parseFilters(filters []string) (filter.Expr, error) {
+	var where filter.Expr
+	for _, f := range filters {
+		if f == "" {
+			continue
+		}
+		e, err := filter.Parse(f)
+		if err != nil {
+			return nil, err
+		}
+		if where == nil {
+			where = e
+		} else {
+			where = filter.And{Left: where, Right: e}
+		}
+	}
+
+	return where, nil
+}
+
+func mapProducts(products []*domain.Product, fields []string) []ProductResponse {
 	outputs := make([]ProductResponse, len(products))
 	for i, p := range products {
-		outputs[i] = ProductResponse{
-			Id:        p.Id,
-			Name:      p.Name,
-			Price:     p.Price,
-			CreatedAt: p.CreatedAt,
-			UpdatedAt: p.UpdatedAt,
-		}
+		outputs[i] = mapProduct(p).only(fields)
 	}
 
 	return outputs
 }
+
+func mapProduct(p *domain.Product) ProductResponse {
+	return ProductResponse{
+		Id:        p.Id,
+		Name:      p.Name,
+		Price:     p.Price,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
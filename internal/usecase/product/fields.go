@@ -0,0 +1,64 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+var (
+	// ErrUnknownField is returned when a request asks for a product field
+	// responses do not have.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrUnknownExpansion is returned for any expansion asked for, products
+	// having no related resources to embed yet.
+	ErrUnknownExpansion = errors.New("unknown expansion")
+)
+
+func validateFields(fields []string) error {
+	for _, field := range fields {
+		if !slices.Contains(domain.ProductColumns, field) {
+			return fmt.Errorf("%w %q, fields are: %s", ErrUnknownField, field, strings.Join(domain.ProductColumns, ", "))
+		}
+	}
+
+	return nil
+}
+
+func validateExpansions(expand []string) error {
+	if len(expand) > 0 {
+		return fmt.Errorf("%w %q, products have no related resources to expand", ErrUnknownExpansion, expand[0])
+	}
+
+	return nil
+}
+
+// only keeps the given fields, every field when there are none. The
+// response fields are never zero for a stored product, so the dropped ones
+// are left out of the JSON.
+func (p ProductResponse) only(fields []string) ProductResponse {
+	if len(fields) == 0 {
+		return p
+	}
+
+	var out ProductResponse
+	for _, field := range fields {
+		switch field {
+		case "id":
+			out.Id = p.Id
+		case "name":
+			out.Name = p.Name
+		case "price":
+			out.Price = p.Price
+		case "created_at":
+			out.CreatedAt = p.CreatedAt
+		case "updated_at":
+			out.UpdatedAt = p.UpdatedAt
+		}
+	}
+
+	return out
+}
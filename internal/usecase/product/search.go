@@ -0,0 +1,85 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type SearchRequest struct {
+	Query      string   `json:"query"`
+	PageNumber int      `json:"page_number"`
+	PageSize   int      `json:"page_size"`
+	Facets     []string `json:"facets"`
+	Fields     []string `json:"fields"`
+	Expand     []string `json:"expand"`
+}
+
+type SearchResponse struct {
+	Products []ProductMatchResponse `json:"products"`
+	// Facets counts all matches, not just the page.
+	Facets *FacetsResponse `json:"facets,omitempty"`
+}
+
+type ProductMatchResponse struct {
+	ProductResponse
+	// Highlight is the name with the matched words wrapped in <mark> tags.
+	Highlight string `json:"highlight"`
+}
+
+type SearchUseCase struct {
+	productRepository domain.ProductRepository
+	facets            facetCounter
+	logger            *slog.Logger
+}
+
+func NewSearchUseCase(productRepository domain.ProductRepository, priceBounds domain.PriceBounds, logger *slog.Logger) *SearchUseCase {
+	return &SearchUseCase{
+		productRepository: productRepository,
+		facets:            facetCounter{productRepository: productRepository, priceBounds: priceBounds},
+		logger:            logger,
+	}
+}
+
+var (
+	errQueryIsRequired = errors.New("query is required")
+)
+
+func (uc *SearchUseCase) Execute(ctx context.Context, r SearchRequest) (SearchResponse, error) {
+	if strings.TrimSpace(r.Query) == "" {
+		return SearchResponse{}, errQueryIsRequired
+	}
+	if err := validateFacets(r.Facets); err != nil {
+		return SearchResponse{}, err
+	}
+	if err := validateFields(r.Fields); err != nil {
+		return SearchResponse{}, err
+	}
+	if err := validateExpansions(r.Expand); err != nil {
+		return SearchResponse{}, err
+	}
+
+	matches, err := uc.productRepository.Search(ctx, r.Query, r.PageNumber, r.PageSize)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	uc.logger.DebugContext(ctx, "products searched", "page_number", r.PageNumber, "page_size", r.PageSize, "count", len(matches))
+
+	products := make([]ProductMatchResponse, len(matches))
+	for i, m := range matches {
+		products[i] = ProductMatchResponse{
+			ProductResponse: mapProduct(m.Product).only(r.Fields),
+			Highlight:       m.Highlight,
+		}
+	}
+
+	facets, err := uc.facets.count(ctx, r.Query, nil, r.Facets)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+
+	return SearchResponse{Products: products, Facets: facets}, nil
+}
@@ -0,0 +1,69 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+const (
+	defaultSuggestions = 10
+	maxSuggestions     = 25
+	maxPrefixLength    = 100
+)
+
+type SuggestRequest struct {
+	Prefix string `json:"prefix"`
+	// Limit defaults to 10 when zero.
+	Limit int `json:"limit"`
+}
+
+type SuggestResponse struct {
+	Suggestions []string `json:"suggestions"`
+}
+
+type SuggestUseCase struct {
+	productRepository domain.ProductRepository
+	logger            *slog.Logger
+}
+
+func NewSuggestUseCase(productRepository domain.ProductRepository, logger *slog.Logger) *SuggestUseCase {
+	return &SuggestUseCase{
+		productRepository: productRepository,
+		logger:            logger,
+	}
+}
+
+var (
+	errPrefixIsRequired = errors.New("prefix is required")
+	errPrefixIsTooLong  = errors.New("prefix must be at most 100 characters")
+	errLimitOutOfRange  = errors.New("limit must be between 1 and 25")
+)
+
+func (uc *SuggestUseCase) Execute(ctx context.Context, r SuggestRequest) (SuggestResponse, error) {
+	prefix := strings.TrimSpace(r.Prefix)
+	limit := r.Limit
+	if limit == 0 {
+		limit = defaultSuggestions
+	}
+
+	switch {
+	case prefix == "":
+		return SuggestResponse{}, errPrefixIsRequired
+	case len([]rune(prefix)) > maxPrefixLength:
+		return SuggestResponse{}, errPrefixIsTooLong
+	case limit < 1 || limit > maxSuggestions:
+		return SuggestResponse{}, errLimitOutOfRange
+	}
+
+	suggestions, err := uc.productRepository.Suggest(ctx, prefix, limit)
+	if err != nil {
+		return SuggestResponse{}, err
+	}
+	uc.logger.DebugContext(ctx, "products suggested", "count", len(suggestions))
+
+	return SuggestResponse{Suggestions: suggestions}, nil
+}
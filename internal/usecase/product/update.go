@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,29 +21,47 @@ type UpdateResponse struct {
 
 type UpdateUseCase struct {
 	productRepository domain.ProductRepository
+	txManager         domain.TxManager
+	eventPublisher    domain.EventPublisher
+	logger            *slog.Logger
 }
 
-func NewUpdateUseCase(productRepository domain.ProductRepository) *UpdateUseCase {
+func NewUpdateUseCase(
+	productRepository domain.ProductRepository,
+	txManager domain.TxManager,
+	eventPublisher domain.EventPublisher,
+	logger *slog.Logger,
+) *UpdateUseCase {
 	return &UpdateUseCase{
 		productRepository: productRepository,
+		txManager:         txManager,
+		eventPublisher:    eventPublisher,
+		logger:            logger,
 	}
 }
 
 func (uc *UpdateUseCase) Execute(ctx context.Context, r UpdateRequest) (UpdateResponse, error) {
-	p, err := uc.productRepository.FetchById(ctx, r.Id)
-	if err != nil {
-		return UpdateResponse{}, err
-	}
+	var p *domain.Product
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		p, err = uc.productRepository.FetchByIdForUpdate(ctx, r.Id)
+		if err != nil {
+			return err
+		}
 
-	p.Name = r.Name
-	p.Price = r.Price
-	p.UpdatedAt = time.Now()
+		p.Name = r.Name
+		p.Price = r.Price
+		p.UpdatedAt = time.Now()
 
-	err = uc.productRepository.Update(ctx, p)
+		return uc.productRepository.Update(ctx, p)
+	})
 	if err != nil {
 		return UpdateResponse{}, err
 	}
 
+	uc.logger.InfoContext(ctx, "product updated", "product_id", p.Id)
+	uc.eventPublisher.Publish(ctx, domain.NewProductEvent(domain.EventProductUpdated, p.Id, p))
+
 	return UpdateResponse{
 		Id: p.Id,
 	}, nil
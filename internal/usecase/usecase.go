@@ -0,0 +1,58 @@
+package usecase
+
+import "context"
+
+// UseCase is the shape shared by every use case in this module, so handlers
+// can depend on it and cross-cutting concerns can wrap any of them.
+type UseCase[Req, Res any] interface {
+	Execute(ctx context.Context, r Req) (Res, error)
+}
+
+// Observer is notified around every execution of an observed use case. The
+// returned function is called with the outcome once Execute returns.
+type Observer interface {
+	Observe(ctx context.Context, name string) (context.Context, func(err error))
+}
+
+// Func adapts a plain Execute function, typically a method value, to UseCase.
+type Func[Req, Res any] func(ctx context.Context, r Req) (Res, error)
+
+func (f Func[Req, Res]) Execute(ctx context.Context, r Req) (Res, error) {
+	return f(ctx, r)
+}
+
+// Observe wraps execute so every observer sees each execution under name.
+// Passing the Execute method value lets the request and response types be
+// inferred.
+func Observe[Req, Res any](name string, execute Func[Req, Res], observers ...Observer) UseCase[Req, Res] {
+	if len(observers) == 0 {
+		return execute
+	}
+
+	return &observed[Req, Res]{
+		name:      name,
+		next:      execute,
+		observers: observers,
+	}
+}
+
+type observed[Req, Res any] struct {
+	name      string
+	next      Func[Req, Res]
+	observers []Observer
+}
+
+func (o *observed[Req, Res]) Execute(ctx context.Context, r Req) (Res, error) {
+	done := make([]func(error), len(o.observers))
+	for i, observer := range o.observers {
+		ctx, done[i] = observer.Observe(ctx, o.name)
+	}
+
+	response, err := o.next(ctx, r)
+
+	for i := len(done) - 1; i >= 0; i-- {
+		done[i](err)
+	}
+
+	return response, err
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type echoUseCase struct {
+	err error
+}
+
+func (uc echoUseCase) Execute(_ context.Context, r string) (string, error) {
+	return r, uc.err
+}
+
+type recordingObserver struct {
+	label string
+	calls *[]string
+}
+
+func (o recordingObserver) Observe(ctx context.Context, name string) (context.Context, func(error)) {
+	*o.calls = append(*o.calls, o.label+" start "+name)
+	return ctx, func(err error) {
+		outcome := "ok"
+		if err != nil {
+			outcome = err.Error()
+		}
+		*o.calls = append(*o.calls, o.label+" end "+outcome)
+	}
+}
+
+func TestObserveWithoutObservers(t *testing.T) {
+	uc := Observe("echo", echoUseCase{}.Execute)
+
+	response, err := uc.Execute(context.Background(), "hi")
+
+	assert.Equal(t, "hi", response)
+	assert.NoError(t, err)
+}
+
+func TestObserveNotifiesObserversInOrder(t *testing.T) {
+	var calls []string
+	uc := Observe("echo", echoUseCase{err: errors.New("boom")}.Execute,
+		recordingObserver{label: "a", calls: &calls},
+		recordingObserver{label: "b", calls: &calls},
+	)
+
+	response, err := uc.Execute(context.Background(), "hi")
+
+	assert.Equal(t, "hi", response)
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, []string{"a start echo", "b start echo", "b end boom", "a end boom"}, calls)
+}
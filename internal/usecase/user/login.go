@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/go-chi/jwtauth"
@@ -22,13 +23,15 @@ type LoginUseCase struct {
 	userRepository domain.UserRepository
 	jwtAuth        *jwtauth.JWTAuth
 	jtwExpiresIn   time.Duration
+	logger         *slog.Logger
 }
 
-func NewLoginUseCase(userRepository domain.UserRepository, jwtAuth *jwtauth.JWTAuth, jtwExpiresIn time.Duration) *LoginUseCase {
+func NewLoginUseCase(userRepository domain.UserRepository, jwtAuth *jwtauth.JWTAuth, jtwExpiresIn time.Duration, logger *slog.Logger) *LoginUseCase {
 	return &LoginUseCase{
 		userRepository: userRepository,
 		jwtAuth:        jwtAuth,
 		jtwExpiresIn:   jtwExpiresIn,
+		logger:         logger,
 	}
 }
 
@@ -40,10 +43,12 @@ var (
 func (uc *LoginUseCase) Execute(ctx context.Context, r LoginRequest) (LoginResponse, error) {
 	user, err := uc.userRepository.FetchByEmail(ctx, r.Email)
 	if err != nil {
+		uc.logger.WarnContext(ctx, "login failed", "reason", "unknown email")
 		return LoginResponse{}, errWrongEmailOrPassword
 	}
 
 	if !user.ValidatePassword(r.Password) {
+		uc.logger.WarnContext(ctx, "login failed", "reason", "wrong password", "user_id", user.Id)
 		return LoginResponse{}, errWrongEmailOrPassword
 	}
 
@@ -52,8 +57,10 @@ func (uc *LoginUseCase) Execute(ctx context.Context, r LoginRequest) (LoginRespo
 		"exp": time.Now().Add(uc.jtwExpiresIn).Unix(),
 	})
 	if err != nil {
+		uc.logger.ErrorContext(ctx, "could not generate token", "user_id", user.Id, "err", err)
 		return LoginResponse{}, errCouldNotGenerateToken
 	}
+	uc.logger.InfoContext(ctx, "user logged in", "user_id", user.Id)
 
 	return LoginResponse{
 		AccessToken: tokenString,
@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"errors"
+	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/rosset7i/product_crud/internal/domain"
@@ -20,11 +21,13 @@ type RegisterResponse struct {
 
 type RegisterUseCase struct {
 	userRepository domain.UserRepository
+	logger         *slog.Logger
 }
 
-func NewRegisterUseCase(userRepository domain.UserRepository) *RegisterUseCase {
+func NewRegisterUseCase(userRepository domain.UserRepository, logger *slog.Logger) *RegisterUseCase {
 	return &RegisterUseCase{
 		userRepository: userRepository,
+		logger:         logger,
 	}
 }
 
@@ -45,8 +48,10 @@ func (uc *RegisterUseCase) Execute(ctx context.Context, r RegisterRequest) (Regi
 	}
 
 	if err = uc.userRepository.Create(ctx, newUser); err != nil {
+		uc.logger.ErrorContext(ctx, "could not persist user", "err", err)
 		return RegisterResponse{}, errCouldNotPersistUser
 	}
+	uc.logger.InfoContext(ctx, "user registered", "user_id", newUser.Id)
 
 	return RegisterResponse{
 		Id: newUser.Id,
@@ -0,0 +1,56 @@
+package view
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type CreateRequest struct {
+	UserId uuid.UUID `json:"-"`
+	Name   string    `json:"name"`
+	// Filter is an expression in the product filter language.
+	Filter string `json:"filter"`
+	// Sort is asc or desc, by name, and defaults to asc.
+	Sort string `json:"sort"`
+	// Columns are the product fields to show, all of them when empty.
+	Columns []string `json:"columns"`
+	// SharedWith are the ids of the other users who can list and run the
+	// view.
+	SharedWith []uuid.UUID `json:"shared_with"`
+}
+
+type CreateResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type CreateUseCase struct {
+	viewRepository domain.ViewRepository
+	logger         *slog.Logger
+}
+
+func NewCreateUseCase(viewRepository domain.ViewRepository, logger *slog.Logger) *CreateUseCase {
+	return &CreateUseCase{
+		viewRepository: viewRepository,
+		logger:         logger,
+	}
+}
+
+func (uc *CreateUseCase) Execute(ctx context.Context, r CreateRequest) (CreateResponse, error) {
+	v, err := domain.NewView(r.UserId, r.Name, r.Filter, r.Sort, r.Columns, r.SharedWith)
+	if err != nil {
+		return CreateResponse{}, err
+	}
+
+	err = uc.viewRepository.Create(ctx, v)
+	if err != nil {
+		return CreateResponse{}, err
+	}
+	uc.logger.InfoContext(ctx, "view created", "view_id", v.Id, "shared_with", len(v.SharedWith))
+
+	return CreateResponse{
+		Id: v.Id,
+	}, nil
+}
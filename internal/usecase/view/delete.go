@@ -0,0 +1,48 @@
+package view
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type DeleteRequest struct {
+	Id     uuid.UUID `json:"id"`
+	UserId uuid.UUID `json:"-"`
+}
+
+type DeleteResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type DeleteUseCase struct {
+	viewRepository domain.ViewRepository
+	logger         *slog.Logger
+}
+
+func NewDeleteUseCase(viewRepository domain.ViewRepository, logger *slog.Logger) *DeleteUseCase {
+	return &DeleteUseCase{
+		viewRepository: viewRepository,
+		logger:         logger,
+	}
+}
+
+func (uc *DeleteUseCase) Execute(ctx context.Context, r DeleteRequest) (DeleteResponse, error) {
+	v, err := fetchVisible(ctx, uc.viewRepository, r.Id, r.UserId)
+	if err != nil {
+		return DeleteResponse{}, err
+	}
+	if v.OwnerId != r.UserId {
+		return DeleteResponse{}, ErrNotOwner
+	}
+
+	err = uc.viewRepository.Delete(ctx, r.Id)
+	if err != nil {
+		return DeleteResponse{}, err
+	}
+	uc.logger.InfoContext(ctx, "view deleted", "view_id", r.Id)
+
+	return DeleteResponse{Id: r.Id}, nil
+}
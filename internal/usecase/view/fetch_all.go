@@ -0,0 +1,83 @@
+package view
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type FetchAllRequest struct {
+	UserId uuid.UUID `json:"-"`
+}
+
+type FetchAllResponse struct {
+	Views []ViewResponse `json:"views"`
+}
+
+type ViewResponse struct {
+	Id      uuid.UUID `json:"id"`
+	OwnerId uuid.UUID `json:"owner_id"`
+	Name    string    `json:"name"`
+	Filter  string    `json:"filter"`
+	Sort    string    `json:"sort"`
+	Columns []string  `json:"columns"`
+	// SharedWith lists who the view is shared with, to its owner only.
+	SharedWith []uuid.UUID `json:"shared_with"`
+	// Owned tells whether the requesting user can change the view.
+	Owned     bool      `json:"owned"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type FetchAllUseCase struct {
+	viewRepository domain.ViewRepository
+	logger         *slog.Logger
+}
+
+func NewFetchAllUseCase(viewRepository domain.ViewRepository, logger *slog.Logger) *FetchAllUseCase {
+	return &FetchAllUseCase{
+		viewRepository: viewRepository,
+		logger:         logger,
+	}
+}
+
+func (uc *FetchAllUseCase) Execute(ctx context.Context, r FetchAllRequest) (FetchAllResponse, error) {
+	views, err := uc.viewRepository.FetchVisible(ctx, r.UserId)
+	if err != nil {
+		return FetchAllResponse{}, err
+	}
+
+	outputs := make([]ViewResponse, len(views))
+	for i, v := range views {
+		outputs[i] = mapView(v, r.UserId)
+	}
+
+	return FetchAllResponse{Views: outputs}, nil
+}
+
+func mapView(v *domain.View, userId uuid.UUID) ViewResponse {
+	columns := v.Columns
+	if columns == nil {
+		columns = []string{}
+	}
+	sharedWith := []uuid.UUID{}
+	if v.OwnerId == userId && v.SharedWith != nil {
+		sharedWith = v.SharedWith
+	}
+
+	return ViewResponse{
+		Id:         v.Id,
+		OwnerId:    v.OwnerId,
+		Name:       v.Name,
+		Filter:     v.Filter,
+		Sort:       v.Sort,
+		Columns:    columns,
+		SharedWith: sharedWith,
+		Owned:      v.OwnerId == userId,
+		CreatedAt:  v.CreatedAt,
+		UpdatedAt:  v.UpdatedAt,
+	}
+}
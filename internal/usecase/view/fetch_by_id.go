@@ -0,0 +1,54 @@
+package view
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type FetchByIdRequest struct {
+	Id     uuid.UUID `json:"id"`
+	UserId uuid.UUID `json:"-"`
+}
+
+type FetchByIdResponse struct {
+	ViewResponse
+}
+
+type FetchByIdUseCase struct {
+	viewRepository domain.ViewRepository
+	logger         *slog.Logger
+}
+
+func NewFetchByIdUseCase(viewRepository domain.ViewRepository, logger *slog.Logger) *FetchByIdUseCase {
+	return &FetchByIdUseCase{
+		viewRepository: viewRepository,
+		logger:         logger,
+	}
+}
+
+func (uc *FetchByIdUseCase) Execute(ctx context.Context, r FetchByIdRequest) (FetchByIdResponse, error) {
+	v, err := fetchVisible(ctx, uc.viewRepository, r.Id, r.UserId)
+	if err != nil {
+		return FetchByIdResponse{}, err
+	}
+
+	return FetchByIdResponse{ViewResponse: mapView(v, r.UserId)}, nil
+}
+
+// fetchVisible fetches a view userId can see. Views of other users that
+// are not shared with userId are reported as not found, so their ids reveal
+// nothing.
+func fetchVisible(ctx context.Context, viewRepository domain.ViewRepository, id, userId uuid.UUID) (*domain.View, error) {
+	v, err := viewRepository.FetchById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !v.VisibleTo(userId) {
+		return nil, domain.ErrViewNotFound
+	}
+
+	return v, nil
+}
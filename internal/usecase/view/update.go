@@ -0,0 +1,75 @@
+package view
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+// ErrNotOwner is returned when a user tries to change a view shared with
+// them.
+var ErrNotOwner = errors.New("only the owner can change a view")
+
+type UpdateRequest struct {
+	Id         uuid.UUID   `json:"id"`
+	UserId     uuid.UUID   `json:"-"`
+	Name       string      `json:"name"`
+	Filter     string      `json:"filter"`
+	Sort       string      `json:"sort"`
+	Columns    []string    `json:"columns"`
+	SharedWith []uuid.UUID `json:"shared_with"`
+}
+
+type UpdateResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type UpdateUseCase struct {
+	viewRepository domain.ViewRepository
+	logger         *slog.Logger
+}
+
+func NewUpdateUseCase(viewRepository domain.ViewRepository, logger *slog.Logger) *UpdateUseCase {
+	return &UpdateUseCase{
+		viewRepository: viewRepository,
+		logger:         logger,
+	}
+}
+
+func (uc *UpdateUseCase) Execute(ctx context.Context, r UpdateRequest) (UpdateResponse, error) {
+	v, err := fetchVisible(ctx, uc.viewRepository, r.Id, r.UserId)
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+	if v.OwnerId != r.UserId {
+		return UpdateResponse{}, ErrNotOwner
+	}
+
+	v.Name = r.Name
+	v.Filter = r.Filter
+	v.Sort = r.Sort
+	if v.Sort == "" {
+		v.Sort = "asc"
+	}
+	v.Columns = r.Columns
+	v.SharedWith = r.SharedWith
+	v.UpdatedAt = time.Now()
+
+	if err = v.Validate(); err != nil {
+		return UpdateResponse{}, err
+	}
+
+	err = uc.viewRepository.Update(ctx, v)
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+	uc.logger.InfoContext(ctx, "view updated", "view_id", v.Id, "shared_with", len(v.SharedWith))
+
+	return UpdateResponse{
+		Id: v.Id,
+	}, nil
+}
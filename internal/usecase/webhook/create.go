@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type CreateRequest struct {
+	UserId uuid.UUID          `json:"-"`
+	Url    string             `json:"url"`
+	Events []domain.EventType `json:"events"`
+	Secret string             `json:"secret"`
+}
+
+type CreateResponse struct {
+	Id     uuid.UUID `json:"id"`
+	Secret string    `json:"secret"`
+}
+
+type CreateUseCase struct {
+	webhookRepository domain.WebhookRepository
+	logger            *slog.Logger
+}
+
+func NewCreateUseCase(webhookRepository domain.WebhookRepository, logger *slog.Logger) *CreateUseCase {
+	return &CreateUseCase{
+		webhookRepository: webhookRepository,
+		logger:            logger,
+	}
+}
+
+func (uc *CreateUseCase) Execute(ctx context.Context, r CreateRequest) (CreateResponse, error) {
+	w, err := domain.NewWebhook(r.UserId, r.Url, r.Events, r.Secret)
+	if err != nil {
+		return CreateResponse{}, err
+	}
+
+	err = uc.webhookRepository.Create(ctx, w)
+	if err != nil {
+		return CreateResponse{}, err
+	}
+	uc.logger.InfoContext(ctx, "webhook created", "webhook_id", w.Id, "url", w.Url)
+
+	return CreateResponse{
+		Id:     w.Id,
+		Secret: w.Secret,
+	}, nil
+}
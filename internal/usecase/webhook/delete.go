@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type DeleteRequest struct {
+	Id     uuid.UUID `json:"id"`
+	UserId uuid.UUID `json:"-"`
+}
+
+type DeleteResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type DeleteUseCase struct {
+	webhookRepository domain.WebhookRepository
+	logger            *slog.Logger
+}
+
+func NewDeleteUseCase(webhookRepository domain.WebhookRepository, logger *slog.Logger) *DeleteUseCase {
+	return &DeleteUseCase{
+		webhookRepository: webhookRepository,
+		logger:            logger,
+	}
+}
+
+func (uc *DeleteUseCase) Execute(ctx context.Context, r DeleteRequest) (DeleteResponse, error) {
+	if _, err := fetchOwned(ctx, uc.webhookRepository, r.Id, r.UserId); err != nil {
+		return DeleteResponse{}, err
+	}
+
+	err := uc.webhookRepository.Delete(ctx, r.Id)
+	if err != nil {
+		return DeleteResponse{}, err
+	}
+	uc.logger.InfoContext(ctx, "webhook deleted", "webhook_id", r.Id)
+
+	return DeleteResponse{Id: r.Id}, nil
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type FetchAllRequest struct {
+	UserId uuid.UUID `json:"-"`
+}
+
+type FetchAllResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookResponse struct {
+	Id        uuid.UUID          `json:"id"`
+	Url       string             `json:"url"`
+	Events    []domain.EventType `json:"events"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+type FetchAllUseCase struct {
+	webhookRepository domain.WebhookRepository
+	logger            *slog.Logger
+}
+
+func NewFetchAllUseCase(webhookRepository domain.WebhookRepository, logger *slog.Logger) *FetchAllUseCase {
+	return &FetchAllUseCase{
+		webhookRepository: webhookRepository,
+		logger:            logger,
+	}
+}
+
+func (uc *FetchAllUseCase) Execute(ctx context.Context, r FetchAllRequest) (FetchAllResponse, error) {
+	webhooks, err := uc.webhookRepository.FetchByOwner(ctx, r.UserId)
+	if err != nil {
+		return FetchAllResponse{}, err
+	}
+
+	return FetchAllResponse{Webhooks: mapWebhooks(webhooks)}, nil
+}
+
+func mapWebhooks(webhooks []*domain.Webhook) []WebhookResponse {
+	outputs := make([]WebhookResponse, len(webhooks))
+	for i, w := range webhooks {
+		outputs[i] = WebhookResponse{
+			Id:        w.Id,
+			Url:       w.Url,
+			Events:    w.Events,
+			CreatedAt: w.CreatedAt,
+			UpdatedAt: w.UpdatedAt,
+		}
+	}
+
+	return outputs
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type FetchByIdRequest struct {
+	Id     uuid.UUID `json:"id"`
+	UserId uuid.UUID `json:"-"`
+}
+
+type FetchByIdResponse struct {
+	Id        uuid.UUID          `json:"id"`
+	Url       string             `json:"url"`
+	Events    []domain.EventType `json:"events"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+type FetchByIdUseCase struct {
+	webhookRepository domain.WebhookRepository
+	logger            *slog.Logger
+}
+
+func NewFetchByIdUseCase(webhookRepository domain.WebhookRepository, logger *slog.Logger) *FetchByIdUseCase {
+	return &FetchByIdUseCase{
+		webhookRepository: webhookRepository,
+		logger:            logger,
+	}
+}
+
+func (uc *FetchByIdUseCase) Execute(ctx context.Context, r FetchByIdRequest) (FetchByIdResponse, error) {
+	w, err := fetchOwned(ctx, uc.webhookRepository, r.Id, r.UserId)
+	if err != nil {
+		return FetchByIdResponse{}, err
+	}
+
+	return FetchByIdResponse{
+		Id:        w.Id,
+		Url:       w.Url,
+		Events:    w.Events,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}, nil
+}
+
+// fetchOwned fetches a webhook userId owns. Webhooks of other users are
+// reported as not found, so their ids reveal nothing.
+func fetchOwned(ctx context.Context, webhookRepository domain.WebhookRepository, id, userId uuid.UUID) (*domain.Webhook, error) {
+	w, err := webhookRepository.FetchById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if w.OwnerId != userId {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	return w, nil
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type FetchDeliveriesRequest struct {
+	WebhookId  uuid.UUID `json:"webhook_id"`
+	UserId     uuid.UUID `json:"-"`
+	PageNumber int       `json:"page_number"`
+	PageSize   int       `json:"page_size"`
+}
+
+type FetchDeliveriesResponse struct {
+	Deliveries []DeliveryResponse `json:"deliveries"`
+}
+
+type DeliveryResponse struct {
+	Id           uuid.UUID             `json:"id"`
+	EventId      uuid.UUID             `json:"event_id"`
+	EventType    domain.EventType      `json:"event_type"`
+	Payload      json.RawMessage       `json:"payload" swaggertype:"object"`
+	Status       domain.DeliveryStatus `json:"status"`
+	Attempts     int                   `json:"attempts"`
+	ResponseCode int                   `json:"response_code"`
+	Error        string                `json:"error"`
+	DeliveredAt  *time.Time            `json:"delivered_at"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+type FetchDeliveriesUseCase struct {
+	webhookRepository  domain.WebhookRepository
+	deliveryRepository domain.WebhookDeliveryRepository
+	logger             *slog.Logger
+}
+
+func NewFetchDeliveriesUseCase(webhookRepository domain.WebhookRepository, deliveryRepository domain.WebhookDeliveryRepository, logger *slog.Logger) *FetchDeliveriesUseCase {
+	return &FetchDeliveriesUseCase{
+		webhookRepository:  webhookRepository,
+		deliveryRepository: deliveryRepository,
+		logger:             logger,
+	}
+}
+
+func (uc *FetchDeliveriesUseCase) Execute(ctx context.Context, r FetchDeliveriesRequest) (FetchDeliveriesResponse, error) {
+	if _, err := fetchOwned(ctx, uc.webhookRepository, r.WebhookId, r.UserId); err != nil {
+		return FetchDeliveriesResponse{}, err
+	}
+
+	deliveries, err := uc.deliveryRepository.FetchPagedByWebhook(ctx, r.WebhookId, r.PageNumber, r.PageSize)
+	if err != nil {
+		return FetchDeliveriesResponse{}, err
+	}
+
+	return FetchDeliveriesResponse{Deliveries: mapDeliveries(deliveries)}, nil
+}
+
+func mapDeliveries(deliveries []*domain.WebhookDelivery) []DeliveryResponse {
+	outputs := make([]DeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		outputs[i] = DeliveryResponse{
+			Id:           d.Id,
+			EventId:      d.EventId,
+			EventType:    d.EventType,
+			Payload:      d.Payload,
+			Status:       d.Status,
+			Attempts:     d.Attempts,
+			ResponseCode: d.ResponseCode,
+			Error:        d.Error,
+			DeliveredAt:  d.DeliveredAt,
+			CreatedAt:    d.CreatedAt,
+			UpdatedAt:    d.UpdatedAt,
+		}
+	}
+
+	return outputs
+}
@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type RedeliverRequest struct {
+	WebhookId  uuid.UUID `json:"webhook_id"`
+	DeliveryId uuid.UUID `json:"delivery_id"`
+	UserId     uuid.UUID `json:"-"`
+}
+
+type RedeliverResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type RedeliverUseCase struct {
+	webhookRepository  domain.WebhookRepository
+	deliveryRepository domain.WebhookDeliveryRepository
+	dispatcher         domain.WebhookDispatcher
+	logger             *slog.Logger
+}
+
+func NewRedeliverUseCase(
+	webhookRepository domain.WebhookRepository,
+	deliveryRepository domain.WebhookDeliveryRepository,
+	dispatcher domain.WebhookDispatcher,
+	logger *slog.Logger,
+) *RedeliverUseCase {
+	return &RedeliverUseCase{
+		webhookRepository:  webhookRepository,
+		deliveryRepository: deliveryRepository,
+		dispatcher:         dispatcher,
+		logger:             logger,
+	}
+}
+
+// Execute queues a new delivery carrying the original payload; the original
+// delivery is kept untouched in the log.
+func (uc *RedeliverUseCase) Execute(ctx context.Context, r RedeliverRequest) (RedeliverResponse, error) {
+	w, err := fetchOwned(ctx, uc.webhookRepository, r.WebhookId, r.UserId)
+	if err != nil {
+		return RedeliverResponse{}, err
+	}
+
+	original, err := uc.deliveryRepository.FetchById(ctx, r.DeliveryId)
+	if err != nil {
+		return RedeliverResponse{}, err
+	}
+	if original.WebhookId != w.Id {
+		return RedeliverResponse{}, domain.ErrWebhookDeliveryNotFound
+	}
+
+	delivery := domain.NewWebhookDelivery(w.Id, original.EventId, original.EventType, original.Payload)
+	if err = uc.deliveryRepository.Create(ctx, delivery); err != nil {
+		return RedeliverResponse{}, err
+	}
+
+	uc.logger.InfoContext(ctx, "webhook redelivery queued", "webhook_id", w.Id, "delivery_id", delivery.Id, "original_delivery_id", original.Id)
+	uc.dispatcher.Dispatch(ctx, w, delivery)
+
+	return RedeliverResponse{
+		Id: delivery.Id,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rosset7i/product_crud/internal/domain"
+)
+
+type UpdateRequest struct {
+	Id     uuid.UUID          `json:"id"`
+	UserId uuid.UUID          `json:"-"`
+	Url    string             `json:"url"`
+	Events []domain.EventType `json:"events"`
+	Secret string             `json:"secret"`
+}
+
+type UpdateResponse struct {
+	Id uuid.UUID `json:"id"`
+}
+
+type UpdateUseCase struct {
+	webhookRepository domain.WebhookRepository
+	logger            *slog.Logger
+}
+
+func NewUpdateUseCase(webhookRepository domain.WebhookRepository, logger *slog.Logger) *UpdateUseCase {
+	return &UpdateUseCase{
+		webhookRepository: webhookRepository,
+		logger:            logger,
+	}
+}
+
+func (uc *UpdateUseCase) Execute(ctx context.Context, r UpdateRequest) (UpdateResponse, error) {
+	w, err := fetchOwned(ctx, uc.webhookRepository, r.Id, r.UserId)
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+
+	w.Url = r.Url
+	w.Events = r.Events
+	if r.Secret != "" {
+		w.Secret = r.Secret
+	}
+	w.UpdatedAt = time.Now()
+
+	if err = w.Validate(); err != nil {
+		return UpdateResponse{}, err
+	}
+
+	err = uc.webhookRepository.Update(ctx, w)
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+	uc.logger.InfoContext(ctx, "webhook updated", "webhook_id", w.Id, "url", w.Url)
+
+	return UpdateResponse{
+		Id: w.Id,
+	}, nil
+}
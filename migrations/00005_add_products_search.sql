@@ -0,0 +1,16 @@
+-- +goose ENVSUB ON
+-- +goose Up
+-- +goose StatementBegin
+-- the text search configuration is fixed when the column is generated, so
+-- changing DB_SEARCH_LANGUAGE later needs a new migration regenerating it
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search tsvector
+    GENERATED ALWAYS AS (to_tsvector('${DB_SEARCH_LANGUAGE:-english}'::regconfig, name)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search ON products USING GIN (search);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_products_search;
+ALTER TABLE products DROP COLUMN search;
+-- +goose StatementEnd
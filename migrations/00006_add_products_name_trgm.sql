@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- names starting with a prefix
+CREATE INDEX IF NOT EXISTS idx_products_name_prefix ON products (lower(name) text_pattern_ops);
+
+-- names closest to a misspelled prefix, ordered by distance straight from
+-- the index
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIST (lower(name) gist_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- the extension stays, other objects may depend on it
+DROP INDEX idx_products_name_trgm;
+DROP INDEX idx_products_name_prefix;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- numbers the events shared through pg_notify so that every instance hands
+-- out the same stream ids
+CREATE SEQUENCE IF NOT EXISTS product_event_seq;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP SEQUENCE IF EXISTS product_event_seq;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- views are shared with a list of users instead of with everyone; views
+-- shared with everyone before become private again, there being no list to
+-- derive from the flag
+ALTER TABLE views ADD COLUMN IF NOT EXISTS shared_with UUID[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_views_shared_with ON views USING GIN (shared_with);
+DROP INDEX IF EXISTS idx_views_shared;
+ALTER TABLE views DROP COLUMN IF EXISTS shared;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE views ADD COLUMN IF NOT EXISTS shared BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE views SET shared = cardinality(shared_with) > 0;
+CREATE INDEX IF NOT EXISTS idx_views_shared ON views (name) WHERE shared;
+DROP INDEX IF EXISTS idx_views_shared_with;
+ALTER TABLE views DROP COLUMN IF EXISTS shared_with;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+package migrations
+
+import "embed"
+
+// FS holds the SQL migrations so the binary knows which version the
+// database is expected to be at.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// SQLite holds the equivalent migrations for the SQLite backend, under the
+// sqlite directory.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
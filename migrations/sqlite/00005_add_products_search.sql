@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- external content table over products, kept in sync by the triggers below;
+-- the porter tokenizer stems English only
+CREATE VIRTUAL TABLE IF NOT EXISTS products_fts USING fts5(
+    name,
+    content='products',
+    tokenize='porter unicode61'
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS products_fts_insert AFTER INSERT ON products BEGIN
+    INSERT INTO products_fts (rowid, name) VALUES (new.rowid, new.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS products_fts_delete AFTER DELETE ON products BEGIN
+    INSERT INTO products_fts (products_fts, rowid, name) VALUES ('delete', old.rowid, old.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS products_fts_update AFTER UPDATE OF name ON products BEGIN
+    INSERT INTO products_fts (products_fts, rowid, name) VALUES ('delete', old.rowid, old.name);
+    INSERT INTO products_fts (rowid, name) VALUES (new.rowid, new.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO products_fts (products_fts) VALUES ('rebuild');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER products_fts_update;
+DROP TRIGGER products_fts_delete;
+DROP TRIGGER products_fts_insert;
+DROP TABLE products_fts;
+-- +goose StatementEnd
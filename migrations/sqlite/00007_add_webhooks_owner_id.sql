@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- webhooks created before owners existed keep a NULL owner: they still
+-- receive deliveries but no user can see or change them
+ALTER TABLE webhooks ADD COLUMN owner_id TEXT REFERENCES users (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_owner_id ON webhooks (owner_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_webhooks_owner_id;
+ALTER TABLE webhooks DROP COLUMN owner_id;
+-- +goose StatementEnd
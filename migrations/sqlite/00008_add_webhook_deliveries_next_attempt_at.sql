@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite cannot add a column defaulting to the current time, so existing
+-- deliveries are backfilled instead
+ALTER TABLE webhook_deliveries ADD COLUMN next_attempt_at TIMESTAMP;
+UPDATE webhook_deliveries SET next_attempt_at = updated_at;
+
+-- deliveries resumed on start
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (created_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_webhook_deliveries_pending;
+ALTER TABLE webhook_deliveries DROP COLUMN next_attempt_at;
+-- +goose StatementEnd
@@ -0,0 +1,102 @@
+-- +goose Up
+-- +goose StatementBegin
+-- products_fts was keyed on the implicit rowid of products, which VACUUM may
+-- renumber since id is not an integer key. The table is rebuilt around an
+-- explicit INTEGER PRIMARY KEY that the index refers to instead.
+DROP TRIGGER products_fts_update;
+DROP TRIGGER products_fts_delete;
+DROP TRIGGER products_fts_insert;
+DROP TABLE products_fts;
+
+CREATE TABLE products_new (
+    search_key INTEGER PRIMARY KEY,
+    id TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    price REAL NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO products_new (id, name, price, created_at, updated_at)
+SELECT id, name, price, created_at, updated_at FROM products;
+DROP TABLE products;
+ALTER TABLE products_new RENAME TO products;
+
+CREATE VIRTUAL TABLE products_fts USING fts5(
+    name,
+    content='products',
+    content_rowid='search_key',
+    tokenize='porter unicode61'
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER products_fts_insert AFTER INSERT ON products BEGIN
+    INSERT INTO products_fts (rowid, name) VALUES (new.search_key, new.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER products_fts_delete AFTER DELETE ON products BEGIN
+    INSERT INTO products_fts (products_fts, rowid, name) VALUES ('delete', old.search_key, old.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER products_fts_update AFTER UPDATE OF name ON products BEGIN
+    INSERT INTO products_fts (products_fts, rowid, name) VALUES ('delete', old.search_key, old.name);
+    INSERT INTO products_fts (rowid, name) VALUES (new.search_key, new.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO products_fts (products_fts) VALUES ('rebuild');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER products_fts_update;
+DROP TRIGGER products_fts_delete;
+DROP TRIGGER products_fts_insert;
+DROP TABLE products_fts;
+
+CREATE TABLE products_old (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    price REAL NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO products_old (id, name, price, created_at, updated_at)
+SELECT id, name, price, created_at, updated_at FROM products;
+DROP TABLE products;
+ALTER TABLE products_old RENAME TO products;
+
+CREATE VIRTUAL TABLE products_fts USING fts5(
+    name,
+    content='products',
+    tokenize='porter unicode61'
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER products_fts_insert AFTER INSERT ON products BEGIN
+    INSERT INTO products_fts (rowid, name) VALUES (new.rowid, new.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER products_fts_delete AFTER DELETE ON products BEGIN
+    INSERT INTO products_fts (products_fts, rowid, name) VALUES ('delete', old.rowid, old.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER products_fts_update AFTER UPDATE OF name ON products BEGIN
+    INSERT INTO products_fts (products_fts, rowid, name) VALUES ('delete', old.rowid, old.name);
+    INSERT INTO products_fts (rowid, name) VALUES (new.rowid, new.name);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO products_fts (products_fts) VALUES ('rebuild');
+-- +goose StatementEnd
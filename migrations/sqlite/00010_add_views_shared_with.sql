@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- views are shared with a JSON array of user ids instead of with everyone;
+-- views shared with everyone before become private again, there being no
+-- list to derive from the flag
+ALTER TABLE views ADD COLUMN shared_with TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE views DROP COLUMN shared;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE views ADD COLUMN shared INTEGER NOT NULL DEFAULT 0;
+UPDATE views SET shared = json_array_length(shared_with) > 0;
+ALTER TABLE views DROP COLUMN shared_with;
+-- +goose StatementEnd